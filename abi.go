@@ -0,0 +1,473 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/0xsequence/ethkit/go-ethereum/accounts/abi"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var ErrUnknownSelector = errors.New("error: no method of the abi matches the calldata selector")
+
+// readABIFile reads a raw abi json file as an artifact without bytecode.
+func readABIFile(path string) (*contractArtifact, error) {
+	abiData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &contractArtifact{ABI: abiData}, nil
+}
+
+const (
+	flagAbiContract = "contract"
+	flagAbiFormat   = "format"
+)
+
+// addABIFlags adds the flags selecting the abi of an artifacts file passed with the abi file flag of a command.
+func addABIFlags(flags *pflag.FlagSet) {
+	flags.String(flagAbiContract, "", "with --abi, the contract of an artifacts file holding several, as Name or path/Foo.sol:Name")
+	flags.String(flagAbiFormat, "", "with --abi, the artifacts file format: truffle, hardhat, foundry, solc-combined or solc-standard-json, default: detected")
+}
+
+// abiSource is a contract artifacts or raw abi json file, along with the format and the contract of the artifacts.
+type abiSource struct {
+	path     string
+	format   string
+	contract string
+}
+
+// abiSourceFor returns the abi source of a command from its abi file flag, or nil when the flag is not set.
+func abiSourceFor(cmd *cobra.Command, flagAbi string) (*abiSource, error) {
+	fAbi, err := cmd.Flags().GetString(flagAbi)
+	if err != nil {
+		return nil, err
+	}
+	fContract, err := cmd.Flags().GetString(flagAbiContract)
+	if err != nil {
+		return nil, err
+	}
+	fFormat, err := cmd.Flags().GetString(flagAbiFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	if fAbi == "" {
+		if fContract != "" || fFormat != "" {
+			return nil, fmt.Errorf("error: --%s and --%s apply to the artifacts file of --%s", flagAbiContract, flagAbiFormat, flagAbi)
+		}
+		return nil, nil
+	}
+	return &abiSource{path: fAbi, format: fFormat, contract: fContract}, nil
+}
+
+// loadABI parses the abi of either a contract artifacts file of any format or a raw abi json file.
+// Raw abi files are recognized by their top-level JSON array.
+func loadABI(src *abiSource) (abi.ABI, error) {
+	data, err := os.ReadFile(src.path)
+	if err != nil {
+		return abi.ABI{}, err
+	}
+
+	var artifact *contractArtifact
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if src.contract != "" || src.format != "" {
+			return abi.ABI{}, fmt.Errorf("error: %s is an abi json file, --%s and --%s apply to artifacts files", src.path, flagAbiContract, flagAbiFormat)
+		}
+		artifact, err = readABIFile(src.path)
+	} else {
+		artifact, err = readArtifactFile(src.path, src.format, src.contract)
+	}
+	if err != nil {
+		return abi.ABI{}, err
+	}
+	if len(artifact.ABI) == 0 {
+		return abi.ABI{}, fmt.Errorf("error: no abi found in %s", src.path)
+	}
+
+	parsed, err := abi.JSON(bytes.NewReader(artifact.ABI))
+	if err != nil {
+		return abi.ABI{}, fmt.Errorf("error: unable to parse abi in %s: %w", src.path, err)
+	}
+
+	return parsed, nil
+}
+
+// DecodedCall is a method call decoded from its calldata.
+type DecodedCall struct {
+	Method    string        `json:"method"`
+	Signature string        `json:"signature"`
+	Selector  hexutil.Bytes `json:"selector"`
+	Args      []*DecodedArg `json:"args"`
+}
+
+// DecodedEvent is an event decoded from a log.
+type DecodedEvent struct {
+	Event     string        `json:"event"`
+	Signature string        `json:"signature"`
+	Args      []*DecodedArg `json:"args"`
+}
+
+// DecodedArg is a named and typed argument of a method call or an event.
+type DecodedArg struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Indexed bool   `json:"indexed,omitempty"`
+	Value   any    `json:"value"`
+}
+
+// String overrides the standard behavior for DecodedCall "to-string".
+func (d *DecodedCall) String() string {
+	var p Printable
+	if err := p.FromStruct(d); err != nil {
+		panic(err)
+	}
+	return p.Columnize(*NewPrintableFormat(20, 0, 0, byte(' ')))
+}
+
+// String overrides the standard behavior for DecodedEvent "to-string".
+func (d *DecodedEvent) String() string {
+	var p Printable
+	if err := p.FromStruct(d); err != nil {
+		panic(err)
+	}
+	return p.Columnize(*NewPrintableFormat(20, 0, 0, byte(' ')))
+}
+
+// decodeCalldata decodes the method and arguments of a calldata.
+func decodeCalldata(contractABI abi.ABI, data []byte) (*DecodedCall, error) {
+	if len(data) < 4 {
+		return nil, errors.New("error: calldata is shorter than a 4-byte selector")
+	}
+
+	method, err := contractABI.MethodById(data[:4])
+	if err != nil {
+		return nil, ErrUnknownSelector
+	}
+
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("error: unable to decode %s arguments: %w", method.Sig, err)
+	}
+
+	call := &DecodedCall{
+		Method:    method.RawName,
+		Signature: method.Sig,
+		Selector:  data[:4],
+		Args:      make([]*DecodedArg, len(method.Inputs)),
+	}
+	for i, input := range method.Inputs {
+		call.Args[i] = &DecodedArg{
+			Name:  input.Name,
+			Type:  input.Type.String(),
+			Value: formatABIValue(input.Type, values[i]),
+		}
+	}
+
+	return call, nil
+}
+
+// decodeLog decodes the event and arguments of a log from its topics and data.
+// Indexed arguments of dynamic types are only available as the hash of their value.
+func decodeLog(contractABI abi.ABI, topics []common.Hash, data []byte) (*DecodedEvent, error) {
+	if len(topics) == 0 {
+		return nil, errors.New("error: anonymous events cannot be decoded, the log has no topics")
+	}
+
+	event, err := contractABI.EventByID(topics[0])
+	if err != nil {
+		return nil, errors.New("error: no event of the abi matches the log topic")
+	}
+
+	values, err := event.Inputs.NonIndexed().Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("error: unable to decode %s data: %w", event.Sig, err)
+	}
+
+	decoded := &DecodedEvent{
+		Event:     event.RawName,
+		Signature: event.Sig,
+		Args:      make([]*DecodedArg, len(event.Inputs)),
+	}
+
+	topicIdx, dataIdx := 1, 0
+	for i, input := range event.Inputs {
+		arg := &DecodedArg{
+			Name:    input.Name,
+			Type:    input.Type.String(),
+			Indexed: input.Indexed,
+		}
+
+		if input.Indexed {
+			if topicIdx >= len(topics) {
+				return nil, fmt.Errorf("error: missing topic for indexed argument %q of %s", input.Name, event.Sig)
+			}
+			topic := topics[topicIdx]
+			topicIdx++
+
+			if isHashedTopicType(input.Type) {
+				arg.Value = topic.Hex()
+			} else {
+				v, err := abi.Arguments{{Type: input.Type}}.Unpack(topic.Bytes())
+				if err != nil {
+					return nil, fmt.Errorf("error: unable to decode topic of %q: %w", input.Name, err)
+				}
+				arg.Value = formatABIValue(input.Type, v[0])
+			}
+		} else {
+			arg.Value = formatABIValue(input.Type, values[dataIdx])
+			dataIdx++
+		}
+
+		decoded.Args[i] = arg
+	}
+
+	return decoded, nil
+}
+
+// isHashedTopicType reports whether an indexed argument of type t is stored as the keccak256 hash of its value.
+func isHashedTopicType(t abi.Type) bool {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return true
+	}
+	return false
+}
+
+// formatABIValue converts a value unpacked by go-ethereum into a value with a readable
+// JSON representation: hex strings for bytes and hashes, decimal strings for big integers
+// and maps for tuples.
+func formatABIValue(t abi.Type, v any) any {
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		if b, ok := v.(*big.Int); ok {
+			return b.String()
+		}
+		return v
+	case abi.AddressTy:
+		return v.(common.Address).Hex()
+	case abi.BytesTy:
+		return hexutil.Encode(v.([]byte))
+	case abi.FixedBytesTy, abi.FunctionTy:
+		rv := reflect.ValueOf(v)
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		return hexutil.Encode(b)
+	case abi.SliceTy, abi.ArrayTy:
+		rv := reflect.ValueOf(v)
+		out := make([]any, rv.Len())
+		for i := range out {
+			out[i] = formatABIValue(*t.Elem, rv.Index(i).Interface())
+		}
+		return out
+	case abi.TupleTy:
+		rv := reflect.Indirect(reflect.ValueOf(v))
+		out := make(map[string]any, len(t.TupleElems))
+		for i, elem := range t.TupleElems {
+			name := t.TupleRawNames[i]
+			if name == "" {
+				name = fmt.Sprintf("%d", i)
+			}
+			out[name] = formatABIValue(*elem, rv.Field(i).Interface())
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// parseABIValues converts human-readable string values into the Go values expected by
+// go-ethereum to pack arguments of the given types.
+func parseABIValues(args abi.Arguments, values []string) ([]any, error) {
+	if len(args) != len(values) {
+		return nil, fmt.Errorf("error: expected %d arguments but got %d", len(args), len(values))
+	}
+
+	out := make([]any, len(args))
+	for i, arg := range args {
+		v, err := parseABIValue(arg.Type, values[i])
+		if err != nil {
+			name := arg.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i)
+			}
+			return nil, fmt.Errorf("error: invalid value for argument %s of type %s: %w", name, arg.Type, err)
+		}
+		out[i] = v
+	}
+
+	return out, nil
+}
+
+// parseABIValue converts a human-readable string value into the Go value expected by go-ethereum
+// to pack an argument of type t. Integers are accepted in decimal or 0x-prefixed hex, bytes in hex,
+// and arrays and tuples as JSON, e.g. ["0x01","0x02"] or {"id":1,"data":"0x"}.
+func parseABIValue(t abi.Type, s string) (any, error) {
+	var v any = s
+	switch t.T {
+	case abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		dec := json.NewDecoder(strings.NewReader(s))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			return nil, fmt.Errorf("expecting a JSON value: %w", err)
+		}
+	}
+
+	rv, err := convertABIValue(t, v)
+	if err != nil {
+		return nil, err
+	}
+	return rv.Interface(), nil
+}
+
+func convertABIValue(t abi.Type, v any) (reflect.Value, error) {
+	switch t.T {
+	case abi.SliceTy, abi.ArrayTy:
+		list, ok := v.([]any)
+		if !ok {
+			return reflect.Value{}, errors.New("expecting a JSON array")
+		}
+		var out reflect.Value
+		if t.T == abi.ArrayTy {
+			if len(list) != t.Size {
+				return reflect.Value{}, fmt.Errorf("expecting %d elements but got %d", t.Size, len(list))
+			}
+			out = reflect.New(t.GetType()).Elem()
+		} else {
+			out = reflect.MakeSlice(t.GetType(), len(list), len(list))
+		}
+		for i, elem := range list {
+			ev, err := convertABIValue(*t.Elem, elem)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("element %d: %w", i, err)
+			}
+			out.Index(i).Set(ev)
+		}
+		return out, nil
+
+	case abi.TupleTy:
+		out := reflect.New(t.TupleType).Elem()
+		switch fields := v.(type) {
+		case []any:
+			if len(fields) != len(t.TupleElems) {
+				return reflect.Value{}, fmt.Errorf("expecting %d tuple fields but got %d", len(t.TupleElems), len(fields))
+			}
+			for i, elem := range t.TupleElems {
+				fv, err := convertABIValue(*elem, fields[i])
+				if err != nil {
+					return reflect.Value{}, fmt.Errorf("field %d: %w", i, err)
+				}
+				out.Field(i).Set(fv)
+			}
+		case map[string]any:
+			for i, elem := range t.TupleElems {
+				field, ok := fields[t.TupleRawNames[i]]
+				if !ok {
+					return reflect.Value{}, fmt.Errorf("missing tuple field %q", t.TupleRawNames[i])
+				}
+				fv, err := convertABIValue(*elem, field)
+				if err != nil {
+					return reflect.Value{}, fmt.Errorf("field %s: %w", t.TupleRawNames[i], err)
+				}
+				out.Field(i).Set(fv)
+			}
+		default:
+			return reflect.Value{}, errors.New("expecting a JSON array or object")
+		}
+		return out, nil
+	}
+
+	s := fmt.Sprint(v)
+
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		n, ok := new(big.Int).SetString(s, 0)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("expecting a number but got %q", s)
+		}
+		if t.T == abi.UintTy && n.Sign() < 0 {
+			return reflect.Value{}, errors.New("expecting an unsigned number")
+		}
+		bits := n.BitLen()
+		if t.T == abi.IntTy && n.Sign() < 0 {
+			bits = new(big.Int).Add(n, big.NewInt(1)).BitLen()
+		}
+		if (t.T == abi.UintTy && bits > t.Size) || (t.T == abi.IntTy && bits > t.Size-1) {
+			return reflect.Value{}, fmt.Errorf("%s overflows %s", s, t)
+		}
+		out := reflect.New(t.GetType()).Elem()
+		switch out.Kind() {
+		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			out.SetInt(n.Int64())
+		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			out.SetUint(n.Uint64())
+		default:
+			out.Set(reflect.ValueOf(n))
+		}
+		return out, nil
+
+	case abi.BoolTy:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("expecting true or false but got %q", s)
+		}
+		return reflect.ValueOf(b), nil
+
+	case abi.StringTy:
+		return reflect.ValueOf(s), nil
+
+	case abi.AddressTy:
+		if !common.IsHexAddress(s) {
+			return reflect.Value{}, fmt.Errorf("expecting an address but got %q", s)
+		}
+		return reflect.ValueOf(common.HexToAddress(s)), nil
+
+	case abi.BytesTy:
+		b, err := hexutil.Decode(s)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("expecting 0x-prefixed hex bytes but got %q", s)
+		}
+		return reflect.ValueOf(b), nil
+
+	case abi.FixedBytesTy:
+		b, err := hexutil.Decode(s)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("expecting 0x-prefixed hex bytes but got %q", s)
+		}
+		if len(b) != t.Size {
+			return reflect.Value{}, fmt.Errorf("expecting %d bytes but got %d", t.Size, len(b))
+		}
+		out := reflect.New(t.GetType()).Elem()
+		reflect.Copy(out, reflect.ValueOf(b))
+		return out, nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("unsupported type %s", t)
+}
+
+// parseTopics parses a comma-separated list of 32-byte topics.
+func parseTopics(s string) ([]common.Hash, error) {
+	var topics []common.Hash
+	for _, t := range strings.Split(s, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		b, err := hexutil.Decode(t)
+		if err != nil || len(b) != common.HashLength {
+			return nil, fmt.Errorf("error: invalid topic %q, topics must be 32-byte hex values", t)
+		}
+		topics = append(topics, common.BytesToHash(b))
+	}
+	return topics, nil
+}
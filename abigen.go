@@ -1,13 +1,15 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"strings"
 
-	"github.com/0xsequence/ethkit/ethartifact"
+	"github.com/0xsequence/ethkit/go-ethereum/accounts/abi"
 	"github.com/0xsequence/ethkit/go-ethereum/accounts/abi/bind"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
 	"github.com/spf13/cobra"
 )
 
@@ -15,45 +17,117 @@ func init() {
 	abigen := &abigen{}
 	cmd := &cobra.Command{
 		Use:   "abigen",
-		Short: "Generate contract Go client code from an abi or truffle artifacts file",
+		Short: "Generate contract Go or TypeScript client code from an abi, or from a truffle, hardhat, foundry or solc artifacts file or directory",
 		Run:   abigen.Run,
 	}
 
-	cmd.Flags().String("artifactsFile", "", "path to truffle contract artifacts file")
+	cmd.Flags().String("artifactsFile", "", "path to contract artifacts file, of any --format")
+	cmd.Flags().String("format", "", "artifacts file format: truffle, hardhat, foundry, solc-combined or solc-standard-json, default: detected")
+	cmd.Flags().String("contract", "", "the contract of an artifacts file holding several, as Name or path/Foo.sol:Name")
 	cmd.Flags().String("abiFile", "", "path to abi json file")
-	cmd.Flags().String("lang", "", "target language, supported: [go], default=go")
+	cmd.Flags().String("lang", "", "target language, supported: [go, ts], default=go")
 	cmd.Flags().String("pkg", "", "pkg (optional)")
 	cmd.Flags().String("type", "", "type (optional)")
 	cmd.Flags().String("outFile", "", "outFile (optional), default=stdout")
 	cmd.Flags().Bool("includeDeployed", false, "include deployed bytecode on the generated file")
+	cmd.Flags().StringArray("link", nil, "link a library into the bytecode, as Name=0xaddress or path/Lib.sol:Name=0xaddress (repeatable)")
+	cmd.Flags().String("libs-file", "", "path to a JSON file mapping library names to their addresses")
+	cmd.Flags().Bool("deploy-libs", false, "generate a Deploy<Type>WithLibraries helper taking the addresses of the libraries left unlinked")
+	cmd.Flags().String("dir", "", "path to an artifacts directory, generating the bindings of all its contracts")
+	cmd.Flags().StringArray("include", nil, "with --dir, only the contracts matching a glob pattern, e.g. 'Token*' (repeatable)")
+	cmd.Flags().StringArray("exclude", nil, "with --dir, leave out the contracts matching a glob pattern, e.g. '*Test*' (repeatable)")
+	cmd.Flags().String("out-dir", "", "with --dir, the directory of a package per contract, or of the single package of --pkg")
 
 	rootCmd.AddCommand(cmd)
 }
 
 type abigen struct {
 	fArtifactsFile   string
+	fFormat          string
+	fContract        string
 	fAbiFile         string
+	fLang            string
 	fPkg             string
 	fType            string
 	fOutFile         string
 	fIncludeDeployed bool
+	fLinks           []string
+	fLibsFile        string
+	fDeployLibs      bool
+	fDir             string
+	fInclude         []string
+	fExclude         []string
+	fOutDir          string
 }
 
 func (c *abigen) Run(cmd *cobra.Command, args []string) {
 	c.fArtifactsFile, _ = cmd.Flags().GetString("artifactsFile")
+	c.fFormat, _ = cmd.Flags().GetString("format")
+	c.fContract, _ = cmd.Flags().GetString("contract")
 	c.fAbiFile, _ = cmd.Flags().GetString("abiFile")
+	c.fLang, _ = cmd.Flags().GetString("lang")
 	c.fPkg, _ = cmd.Flags().GetString("pkg")
 	c.fType, _ = cmd.Flags().GetString("type")
 	c.fOutFile, _ = cmd.Flags().GetString("outFile")
 	c.fIncludeDeployed, _ = cmd.Flags().GetBool("includeDeployed")
+	c.fLinks, _ = cmd.Flags().GetStringArray("link")
+	c.fLibsFile, _ = cmd.Flags().GetString("libs-file")
+	c.fDeployLibs, _ = cmd.Flags().GetBool("deploy-libs")
+	c.fDir, _ = cmd.Flags().GetString("dir")
+	c.fInclude, _ = cmd.Flags().GetStringArray("include")
+	c.fExclude, _ = cmd.Flags().GetStringArray("exclude")
+	c.fOutDir, _ = cmd.Flags().GetString("out-dir")
+
+	switch c.fLang {
+	case "":
+		c.fLang = abigenLangGo
+	case abigenLangGo, abigenLangTS:
+	default:
+		log.Fatalf("error: unknown --lang %q, supported: %s", c.fLang, strings.Join(abigenLanguages, ", "))
+		return
+	}
+	if c.fLang != abigenLangGo && c.fDeployLibs {
+		log.Fatal("error: --deploy-libs is only supported with --lang go")
+		return
+	}
+
+	if c.fDir != "" {
+		if c.fArtifactsFile != "" || c.fAbiFile != "" {
+			log.Fatal("error: --dir can not be combined with --artifactsFile or --abiFile")
+			return
+		}
+		if c.fType != "" || c.fOutFile != "" || c.fContract != "" {
+			log.Fatal("error: --type, --outFile and --contract apply to a single contract, please use --include and --out-dir with --dir")
+			return
+		}
+		if c.fOutDir == "" {
+			log.Fatal("error: please pass --out-dir")
+			return
+		}
+
+		summary, err := c.generateDir()
+		if err != nil {
+			log.Fatal(err)
+			return
+		}
+		if err := printResult(cmd, summary); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(c.fInclude) > 0 || len(c.fExclude) > 0 || c.fOutDir != "" {
+		log.Fatal("error: --include, --exclude and --out-dir apply to --dir")
+		return
+	}
 
 	if c.fArtifactsFile == "" && c.fAbiFile == "" {
-		fmt.Println("error: please pass one of --artifactsFile or --abiFile")
+		fmt.Println("error: please pass one of --artifactsFile, --abiFile or --dir")
 		help(cmd)
 		return
 	}
 
-	if c.fAbiFile != "" && c.fPkg == "" {
+	if c.fAbiFile != "" && c.fPkg == "" && c.fLang == abigenLangGo {
 		fmt.Println("error: please pass --pkg")
 		help(cmd)
 		return
@@ -64,45 +138,27 @@ func (c *abigen) Run(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	var artifact ethartifact.RawArtifact
+	var artifact *contractArtifact
 	var err error
 
 	if c.fArtifactsFile != "" {
-		artifact, err = ethartifact.ParseArtifactFile(c.fArtifactsFile)
-		if err != nil {
-			log.Fatal(err)
-			return
-		}
+		artifact, err = readArtifactFile(c.fArtifactsFile, c.fFormat, c.fContract)
 	} else {
-		abiData, err := os.ReadFile(c.fAbiFile)
-		if err != nil {
-			log.Fatal(err)
-			return
-		}
-		artifact = ethartifact.RawArtifact{ABI: abiData}
+		artifact, err = readABIFile(c.fAbiFile)
 	}
-
-	if err := c.generateGo(artifact); err != nil {
+	if err != nil {
 		log.Fatal(err)
 		return
 	}
-}
 
-func (c *abigen) generateGo(artifact ethartifact.RawArtifact) error {
-	var (
-		abis  []string
-		bins  []string
-		dbins []string
-		types []string
-		sigs  []map[string]string
-		libs  = make(map[string]string)
-		lang  = bind.LangGo
-	)
-
-	if strings.Contains(string(artifact.Bytecode), "//") {
-		log.Fatal("Contract has additional library references, which is unsupported at this time.")
+	if err := c.generate(artifact); err != nil {
+		log.Fatal(err)
+		return
 	}
+}
 
+// generate writes the client code of a contract in the --lang target language to --outFile, or to stdout.
+func (c *abigen) generate(artifact *contractArtifact) error {
 	var pkgName string
 	if c.fPkg != "" {
 		pkgName = c.fPkg
@@ -117,22 +173,19 @@ func (c *abigen) generateGo(artifact ethartifact.RawArtifact) error {
 		typeName = artifact.ContractName
 	}
 
-	types = append(types, typeName)
-	abis = append(abis, string(artifact.ABI))
-	bins = append(bins, artifact.Bytecode)
-	aliases := map[string]string{}
-
-	if c.fIncludeDeployed {
-		dbins = append(dbins, artifact.DeployedBytecode)
-
-		if strings.Contains(string(artifact.DeployedBytecode), "//") {
-			log.Fatal("Contract has additional library references, which is unsupported at this time.")
-		}
-	} else {
-		dbins = append(dbins, "")
+	links, err := readLibraryLinks(c.fLibsFile, c.fLinks)
+	if err != nil {
+		return err
+	}
+	contract, err := c.linkContract(artifact, typeName, links)
+	if err != nil {
+		return err
+	}
+	if c.fDeployLibs && len(contract.unresolved) == 0 {
+		return errors.New("error: --deploy-libs needs libraries left unlinked, the bytecode references none or all of them are linked")
 	}
 
-	code, err := bind.Bind(types, abis, bins, dbins, sigs, pkgName, lang, libs, aliases)
+	code, err := c.bind([]*abigenContract{contract}, pkgName)
 	if err != nil {
 		return err
 	}
@@ -147,3 +200,88 @@ func (c *abigen) generateGo(artifact ethartifact.RawArtifact) error {
 
 	return nil
 }
+
+// abigenContract is a contract to bind, with its bytecodes linked to the libraries of --link and --libs-file.
+type abigenContract struct {
+	artifact         *contractArtifact
+	typeName         string
+	bytecode         string
+	deployedBytecode string
+	unresolved       []*libraryPlaceholder // the libraries left unlinked, for --deploy-libs
+}
+
+// linkContract links the bytecodes of a contract, failing on libraries without an address unless --deploy-libs.
+func (c *abigen) linkContract(artifact *contractArtifact, typeName string, links map[string]common.Address) (*abigenContract, error) {
+	// the libraries are linked here rather than by bind, which would deploy them along with the contract
+	names := artifact.libraryNames()
+	bytecode, unresolved, err := linkBytecode(artifact.Bytecode, names, links)
+	if err != nil {
+		return nil, err
+	}
+	if len(unresolved) > 0 && !c.fDeployLibs {
+		return nil, unresolvedLibrariesError(typeName, unresolved)
+	}
+	contract := &abigenContract{artifact: artifact, typeName: typeName, bytecode: bytecode, unresolved: unresolved}
+
+	if c.fIncludeDeployed {
+		deployedBytecode, deployedUnresolved, err := linkBytecode(artifact.DeployedBytecode, names, links)
+		if err != nil {
+			return nil, err
+		}
+		if len(deployedUnresolved) > 0 && !c.fDeployLibs {
+			return nil, unresolvedLibrariesError(typeName, deployedUnresolved)
+		}
+		contract.deployedBytecode = deployedBytecode
+	}
+
+	return contract, nil
+}
+
+// bind generates the client code of contracts in the --lang target language.
+func (c *abigen) bind(contracts []*abigenContract, pkgName string) (string, error) {
+	switch c.fLang {
+	case abigenLangTS:
+		return bindTS(contracts)
+	default:
+		return c.bindGo(contracts, pkgName)
+	}
+}
+
+// bindGo generates a Go package holding the bindings of contracts, the structs of their ABIs declared once.
+func (c *abigen) bindGo(contracts []*abigenContract, pkgName string) (string, error) {
+	var (
+		abis  []string
+		bins  []string
+		dbins []string
+		types []string
+		sigs  []map[string]string
+		libs  = make(map[string]string)
+		lang  = bind.LangGo
+	)
+
+	for _, contract := range contracts {
+		types = append(types, contract.typeName)
+		abis = append(abis, string(contract.artifact.ABI))
+		bins = append(bins, contract.bytecode)
+		dbins = append(dbins, contract.deployedBytecode)
+	}
+	aliases := map[string]string{}
+
+	code, err := bind.Bind(types, abis, bins, dbins, sigs, pkgName, lang, libs, aliases)
+	if err != nil {
+		return "", err
+	}
+
+	if c.fDeployLibs {
+		for _, contract := range contracts {
+			if len(contract.unresolved) == 0 {
+				continue
+			}
+			if code, err = generateDeployWithLibraries(code, abi.ToCamelCase(contract.typeName), contract.unresolved); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return code, nil
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/0xsequence/ethkit/go-ethereum/accounts/abi"
+)
+
+// status of a contract of an abigen --dir
+const (
+	abigenGenerated = "generated"
+	abigenUnchanged = "unchanged"
+	abigenSkipped   = "skipped"
+)
+
+// AbigenResult is the outcome of the generation of the bindings of a contract of an artifacts directory.
+type AbigenResult struct {
+	Contract string `json:"contract,omitempty"`
+	File     string `json:"file"`
+	Output   string `json:"output,omitempty"`
+	Status   string `json:"status"`
+	Reason   string `json:"reason,omitempty"`
+
+	contract *abigenContract
+}
+
+// AbigenSummary is the outcome of an abigen --dir, contracts left out by --include and --exclude being only counted.
+type AbigenSummary struct {
+	Contracts []*AbigenResult `json:"contracts"`
+	Generated int             `json:"generated"`
+	Unchanged int             `json:"unchanged"`
+	Skipped   int             `json:"skipped"`
+	Excluded  int             `json:"excluded"`
+}
+
+// String overrides the standard behavior for AbigenSummary "to-string", printing a row per contract followed by
+// the counts.
+func (s *AbigenSummary) String() string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CONTRACT\tSTATUS\tOUTPUT")
+	for _, r := range s.Contracts {
+		contract, output := r.Contract, r.Output
+		if contract == "" {
+			contract = r.File
+		}
+		if r.Status == abigenSkipped {
+			output = r.Reason
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", contract, r.Status, output)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(&b, "=> %d generated, %d unchanged, %d skipped, %d excluded by --include/--exclude", s.Generated, s.Unchanged, s.Skipped, s.Excluded)
+	return b.String()
+}
+
+// generateDir generates the client code of the contracts of an artifacts directory matching --include and
+// --exclude, in a package or module per contract under --out-dir, or all in the single one of --pkg. Files are only
+// written when their content changed, keeping the modification times of go:generate runs stable.
+func (c *abigen) generateDir() (*AbigenSummary, error) {
+	for _, pattern := range append(append([]string{}, c.fInclude...), c.fExclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("error: invalid pattern %q: %w", pattern, err)
+		}
+	}
+	links, err := readLibraryLinks(c.fLibsFile, c.fLinks)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &AbigenSummary{Contracts: []*AbigenResult{}}
+	contracts := []*abigenContract{}
+	// bind capitalises the type names, which must be unique within a package and across the package directories
+	types := map[string]string{}
+
+	err = filepath.WalkDir(c.fDir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(file) != ".json" {
+			return nil
+		}
+
+		artifacts, err := readArtifacts(file, c.fFormat)
+		if errors.Is(err, errUnknownArtifactsFormat) {
+			// build infos, hardhat debug files and other JSON files sit next to the artifacts
+			return nil
+		}
+		if err != nil {
+			summary.Contracts = append(summary.Contracts, &AbigenResult{File: file, Status: abigenSkipped, Reason: strings.TrimPrefix(err.Error(), "error: ")})
+			return nil
+		}
+
+		for _, artifact := range artifacts {
+			if !c.included(artifact) {
+				summary.Excluded++
+				continue
+			}
+			result := &AbigenResult{Contract: artifact.FullName(), File: file}
+			summary.Contracts = append(summary.Contracts, result)
+
+			typeName := abi.ToCamelCase(artifact.ContractName)
+			if other, ok := types[strings.ToLower(typeName)]; ok {
+				result.Status, result.Reason = abigenSkipped, fmt.Sprintf("same type name as %s, please --exclude one of them", other)
+				continue
+			}
+			contract, err := c.linkContract(artifact, artifact.ContractName, links)
+			if err != nil {
+				result.Status, result.Reason = abigenSkipped, strings.TrimPrefix(err.Error(), "error: ")
+				continue
+			}
+			types[strings.ToLower(typeName)] = artifact.FullName()
+			result.contract = contract
+			contracts = append(contracts, contract)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(summary.Contracts) == 0 && summary.Excluded == 0 {
+		return nil, fmt.Errorf("error: no artifacts found in %s", c.fDir)
+	}
+
+	switch {
+	case c.fPkg != "" && len(contracts) > 0:
+		// a single package declares the structs shared by the ABIs once
+		output := filepath.Join(c.fOutDir, c.fPkg+"."+c.fileExtension())
+		code, err := c.bind(contracts, c.fPkg)
+		if err != nil {
+			return nil, err
+		}
+		changed, err := writeFileIfChanged(output, []byte(code))
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range summary.Contracts {
+			if result.contract != nil {
+				result.Output, result.Status = output, changedStatus(changed)
+			}
+		}
+
+	case c.fPkg == "":
+		for _, result := range summary.Contracts {
+			if result.contract == nil {
+				continue
+			}
+			pkgName := strings.ToLower(result.contract.artifact.ContractName)
+			code, err := c.bind([]*abigenContract{result.contract}, pkgName)
+			if err != nil {
+				result.Status, result.Reason = abigenSkipped, strings.TrimPrefix(err.Error(), "error: ")
+				continue
+			}
+			// a TypeScript module per contract sits directly in --out-dir
+			output := filepath.Join(c.fOutDir, pkgName, pkgName+".go")
+			if c.fLang == abigenLangTS {
+				output = filepath.Join(c.fOutDir, abi.ToCamelCase(result.contract.typeName)+".ts")
+			}
+			changed, err := writeFileIfChanged(output, []byte(code))
+			if err != nil {
+				return nil, err
+			}
+			result.Output, result.Status = output, changedStatus(changed)
+		}
+	}
+
+	for _, result := range summary.Contracts {
+		switch result.Status {
+		case abigenGenerated:
+			summary.Generated++
+		case abigenUnchanged:
+			summary.Unchanged++
+		default:
+			summary.Skipped++
+		}
+	}
+	return summary, nil
+}
+
+// included returns whether a contract matches one of --include, if any, and none of --exclude. The glob patterns
+// match either the contract name or its fully qualified name.
+func (c *abigen) included(artifact *contractArtifact) bool {
+	matches := func(patterns []string) bool {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, artifact.ContractName); ok {
+				return true
+			}
+			if ok, _ := path.Match(pattern, artifact.FullName()); ok {
+				return true
+			}
+		}
+		return false
+	}
+	return (len(c.fInclude) == 0 || matches(c.fInclude)) && !matches(c.fExclude)
+}
+
+// fileExtension returns the extension of the files of the --lang target language.
+func (c *abigen) fileExtension() string {
+	if c.fLang == abigenLangTS {
+		return "ts"
+	}
+	return "go"
+}
+
+func changedStatus(changed bool) string {
+	if changed {
+		return abigenGenerated
+	}
+	return abigenUnchanged
+}
+
+// writeFileIfChanged writes a file, creating its directory, unless it already holds the data.
+func writeFileIfChanged(file string, data []byte) (bool, error) {
+	current, err := os.ReadFile(file)
+	if err == nil && bytes.Equal(current, data) {
+		return false, nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		return false, err
+	}
+	return true, nil
+}
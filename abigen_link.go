@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/crypto"
+)
+
+// libraryPlaceholderLength is the length of a library placeholder, replaced by the 40 hex characters of an address.
+const libraryPlaceholderLength = 2 * common.AddressLength
+
+var solcPlaceholderPattern = regexp.MustCompile(`^__\$([0-9a-f]{34})\$__$`)
+
+// libraryPlaceholder is a reference to a library left in unlinked bytecode, either a legacy __Name____ placeholder
+// of solc < 0.5 and truffle, or a __$hash$__ placeholder of solc >= 0.5.
+type libraryPlaceholder struct {
+	Placeholder string // the 40 characters replaced by the library address
+	Name        string // the library name of a legacy placeholder, possibly fully qualified and truncated
+	Hash        string // the 34 hex characters of the keccak256 hash of the fully qualified library name
+}
+
+// String returns the library name, or the placeholder when only its hash is known.
+func (p *libraryPlaceholder) String() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.Placeholder
+}
+
+// matches returns whether a library name, e.g. Math or contracts/Math.sol:Math, is the library of the placeholder.
+func (p *libraryPlaceholder) matches(name string) bool {
+	if p.Hash != "" && p.Hash == libraryPlaceholderHash(name) {
+		return true
+	}
+	if p.Name == "" {
+		return false
+	}
+	// legacy placeholders truncate the name to fit in 36 characters
+	if name == p.Name || (len(name) > len(p.Name) && len(p.Name) == libraryPlaceholderLength-4 && strings.HasPrefix(name, p.Name)) {
+		return true
+	}
+	return libraryShortName(name) == libraryShortName(p.Name)
+}
+
+// fieldName returns the Go field name of the library address in the generated deploy helper.
+func (p *libraryPlaceholder) fieldName() string {
+	if p.Name == "" {
+		return "Library" + p.Hash[:8]
+	}
+	name := []rune(libraryShortName(p.Name))
+	for i, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			name[i] = '_'
+		}
+	}
+	if !unicode.IsLetter(name[0]) {
+		return "Library" + string(name)
+	}
+	return strings.ToUpper(string(name[:1])) + string(name[1:])
+}
+
+// libraryShortName returns the name of a library without its source file, e.g. Math for contracts/Math.sol:Math.
+func libraryShortName(name string) string {
+	return name[strings.LastIndex(name, ":")+1:]
+}
+
+// libraryPlaceholderHash returns the hash of a __$hash$__ placeholder, the first 17 bytes of the keccak256 hash of
+// the fully qualified library name.
+func libraryPlaceholderHash(name string) string {
+	return hex.EncodeToString(crypto.Keccak256([]byte(name)))[:34]
+}
+
+// findLibraryPlaceholders returns the distinct library placeholders of a bytecode, in order of appearance, named
+// after the link references of the artifact when known. The hex of the bytecode never holds an underscore, which
+// starts every placeholder.
+func findLibraryPlaceholders(bytecode string, names map[string]string) ([]*libraryPlaceholder, error) {
+	placeholders := []*libraryPlaceholder{}
+	seen := map[string]bool{}
+	for i := strings.Index(bytecode, "__"); i >= 0; i = strings.Index(bytecode, "__") {
+		if len(bytecode) < i+libraryPlaceholderLength {
+			return nil, fmt.Errorf("error: truncated library placeholder %q in the bytecode", bytecode[i:])
+		}
+		s := bytecode[i : i+libraryPlaceholderLength]
+		bytecode = bytecode[i+libraryPlaceholderLength:]
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+
+		if m := solcPlaceholderPattern.FindStringSubmatch(s); m != nil {
+			placeholders = append(placeholders, &libraryPlaceholder{Placeholder: s, Name: names[s], Hash: m[1]})
+			continue
+		}
+		name := strings.TrimRight(s[2:], "_")
+		if name == "" || strings.ContainsAny(name, "$") {
+			return nil, fmt.Errorf("error: invalid library placeholder %q in the bytecode", s)
+		}
+		placeholders = append(placeholders, &libraryPlaceholder{Placeholder: s, Name: name})
+	}
+	return placeholders, nil
+}
+
+// linkBytecode replaces the placeholders of a bytecode by the address of their library, and returns the placeholders
+// of the libraries without an address.
+func linkBytecode(bytecode string, names map[string]string, links map[string]common.Address) (string, []*libraryPlaceholder, error) {
+	placeholders, err := findLibraryPlaceholders(bytecode, names)
+	if err != nil {
+		return "", nil, err
+	}
+
+	linked := make([]string, 0, len(links))
+	for name := range links {
+		linked = append(linked, name)
+	}
+	sort.Strings(linked)
+
+	unresolved := []*libraryPlaceholder{}
+	for _, p := range placeholders {
+		address, ok := links[p.Name]
+		if !ok || p.Name == "" {
+			for _, name := range linked {
+				if p.matches(name) {
+					address, ok = links[name], true
+					break
+				}
+			}
+		}
+		if !ok {
+			unresolved = append(unresolved, p)
+			continue
+		}
+		bytecode = strings.ReplaceAll(bytecode, p.Placeholder, strings.ToLower(address.Hex()[2:]))
+	}
+	return bytecode, unresolved, nil
+}
+
+// readLibraryLinks returns the library addresses of a --libs-file, a JSON object of library names to addresses,
+// overridden by the Name=0xaddress values of --link.
+func readLibraryLinks(libsFile string, links []string) (map[string]common.Address, error) {
+	addresses := map[string]common.Address{}
+
+	if libsFile != "" {
+		data, err := os.ReadFile(libsFile)
+		if err != nil {
+			return nil, err
+		}
+		var libs map[string]string
+		if err := json.Unmarshal(data, &libs); err != nil {
+			return nil, fmt.Errorf("error: invalid --libs-file %s, expecting a JSON object of library names to addresses: %w", libsFile, err)
+		}
+		for name, address := range libs {
+			if !common.IsHexAddress(address) {
+				return nil, fmt.Errorf("error: invalid address %q of library %s in %s", address, name, libsFile)
+			}
+			addresses[name] = common.HexToAddress(address)
+		}
+	}
+
+	for _, link := range links {
+		name, address, ok := strings.Cut(link, "=")
+		if !ok || name == "" || !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("error: invalid --link %q, expecting Name=0xaddress", link)
+		}
+		addresses[name] = common.HexToAddress(address)
+	}
+
+	return addresses, nil
+}
+
+// unresolvedLibrariesError lists the libraries of a contract left without an address.
+func unresolvedLibrariesError(contract string, unresolved []*libraryPlaceholder) error {
+	names := make([]string, len(unresolved))
+	hint := ""
+	for i, p := range unresolved {
+		names[i] = p.String()
+		if p.Name == "" {
+			hint = " (a __$hash$__ placeholder is linked by the fully qualified library name, e.g. --link contracts/Math.sol:Math=0x...)"
+		}
+	}
+	return fmt.Errorf("error: %s references libraries without an address: %s, please pass them with --link Name=0xaddress or --libs-file, or generate a deploy helper with --deploy-libs%s",
+		contract, strings.Join(names, ", "), hint)
+}
+
+var deployWithLibrariesTemplate = template.Must(template.New("").Parse(`
+// {{.Type}}Libraries are the addresses of the libraries linked into {{.Type}} on deployment.
+type {{.Type}}Libraries struct {
+{{- range .Libraries}}
+	{{.Field}} common.Address // {{.Name}}
+{{- end}}
+}
+
+// Deploy{{.Type}}WithLibraries deploys a new Ethereum contract linked to the given libraries, binding an instance of {{.Type}} to it.
+func Deploy{{.Type}}WithLibraries(auth *bind.TransactOpts, backend bind.ContractBackend, libraries {{.Type}}Libraries{{.Params}}) (common.Address, *types.Transaction, *{{.Type}}, error) {
+	parsed, err := {{.Type}}MetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if parsed == nil {
+		return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+	}
+	bin := {{.Type}}MetaData.Bin
+{{- range .Libraries}}
+	bin = strings.ReplaceAll(bin, "{{.Placeholder}}", strings.ToLower(libraries.{{.Field}}.Hex()[2:]))
+{{- end}}
+	address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex(bin), backend{{.Args}})
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &{{.Type}}{ {{.Type}}Caller: {{.Type}}Caller{contract: contract}, {{.Type}}Transactor: {{.Type}}Transactor{contract: contract}, {{.Type}}Filterer: {{.Type}}Filterer{contract: contract} }, nil
+}
+`))
+
+// generateDeployWithLibraries appends to the Go binding of a contract a Deploy<Type>WithLibraries helper, taking
+// the addresses of the unlinked libraries along with the constructor arguments of the generated Deploy<Type>, which
+// is left returning an error pointing to the helper.
+func generateDeployWithLibraries(code, typeName string, libraries []*libraryPlaceholder) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", code, 0)
+	if err != nil {
+		return "", err
+	}
+
+	var deploy *ast.FuncDecl
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "Deploy"+typeName {
+			deploy = fn
+		}
+	}
+	if deploy == nil {
+		return "", fmt.Errorf("error: no Deploy%s function was generated, the contract has no bytecode", typeName)
+	}
+
+	// the constructor parameters follow the auth and backend parameters
+	var params, args string
+	if fields := deploy.Type.Params.List; len(fields) > 2 {
+		params = ", " + code[fset.Position(fields[2].Pos()).Offset:fset.Position(fields[len(fields)-1].End()).Offset]
+		for _, field := range fields[2:] {
+			for _, name := range field.Names {
+				args += ", " + name.Name
+			}
+		}
+	}
+
+	// the Deploy<Type> generated would deploy the bytecode with its placeholders, which common.FromHex drops
+	body := fmt.Sprintf(`{
+	return common.Address{}, nil, nil, errors.New("%s links libraries on deployment, please use Deploy%sWithLibraries")
+}`, typeName, typeName)
+	code = code[:fset.Position(deploy.Body.Lbrace).Offset] + body + code[fset.Position(deploy.Body.Rbrace).Offset+1:]
+
+	type library struct{ Field, Name, Placeholder string }
+	data := struct {
+		Type, Params, Args string
+		Libraries          []library
+	}{Type: typeName, Params: params, Args: args}
+	fields := map[string]bool{}
+	for _, p := range libraries {
+		field := p.fieldName()
+		for fields[field] {
+			field += "_"
+		}
+		fields[field] = true
+		data.Libraries = append(data.Libraries, library{Field: field, Name: p.String(), Placeholder: p.Placeholder})
+	}
+
+	buf := bytes.NewBufferString(code)
+	if err := deployWithLibrariesTemplate.Execute(buf, data); err != nil {
+		return "", err
+	}
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/0xsequence/ethkit/go-ethereum/accounts/abi"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// testLinkedABI is the abi of a contract whose constructor takes arguments, to check the deploy helper parameters.
+const testLinkedABI = `[{"type":"constructor","inputs":[{"name":"owner","type":"address"},{"name":"amount","type":"uint256"}],"stateMutability":"nonpayable"},{"type":"function","name":"total","inputs":[],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"}]`
+
+// testLinkedBytecode references the contracts/Math.sol:Math library with a solc placeholder, twice, and the Strings
+// library with a legacy placeholder.
+var testLinkedBytecode = "0x608060405273" + "__$" + libraryPlaceholderHash("contracts/Math.sol:Math") + "$__" + "600073" +
+	"__Strings_______________________________" + "73" + "__$" + libraryPlaceholderHash("contracts/Math.sol:Math") + "$__" + "00"
+
+func Test_FindLibraryPlaceholders(t *testing.T) {
+	placeholders, err := findLibraryPlaceholders(testLinkedBytecode, nil)
+	assert.Nil(t, err)
+	assert.Len(t, placeholders, 2)
+	assert.Equal(t, "6ad30996409d058139477db06ae39abaac", placeholders[0].Hash)
+	assert.Equal(t, "", placeholders[0].Name)
+	assert.Equal(t, "Strings", placeholders[1].Name)
+
+	assert.True(t, placeholders[0].matches("contracts/Math.sol:Math"))
+	assert.False(t, placeholders[0].matches("Math"))
+	assert.True(t, placeholders[1].matches("Strings"))
+	assert.True(t, placeholders[1].matches("contracts/Strings.sol:Strings"))
+
+	// legacy placeholders truncate fully qualified names to 36 characters
+	p := &libraryPlaceholder{Name: "contracts/libraries/VeryLongLibraryN"}
+	assert.True(t, p.matches("contracts/libraries/VeryLongLibraryName.sol:VeryLongLibraryName"))
+
+	_, err = findLibraryPlaceholders("0x6080__Math__", nil)
+	assert.NotNil(t, err)
+}
+
+func Test_LinkBytecode(t *testing.T) {
+	math := common.HexToAddress("0x00000000000000000000000000000000000000Bb")
+	strs := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+	linked, unresolved, err := linkBytecode(testLinkedBytecode, nil, map[string]common.Address{"contracts/Math.sol:Math": math, "Strings": strs})
+	assert.Nil(t, err)
+	assert.Empty(t, unresolved)
+	assert.Equal(t, "0x608060405273"+strings.Repeat("0", 38)+"bb600073"+strings.Repeat("0", 38)+"aa73"+strings.Repeat("0", 38)+"bb00", linked)
+
+	linked, unresolved, err = linkBytecode(testLinkedBytecode, nil, map[string]common.Address{"Strings": strs})
+	assert.Nil(t, err)
+	assert.Len(t, unresolved, 1)
+	assert.Contains(t, linked, "__$6ad30996409d058139477db06ae39abaac$__")
+	assert.Contains(t, unresolvedLibrariesError("Vault", unresolved).Error(), "--link contracts/Math.sol:Math=0x...")
+}
+
+func Test_ReadLibraryLinks(t *testing.T) {
+	libsFile := filepath.Join(t.TempDir(), "libs.json")
+	assert.Nil(t, os.WriteFile(libsFile, []byte(`{"Math": "0x00000000000000000000000000000000000000bb", "Strings": "0x00000000000000000000000000000000000000aa"}`), 0600))
+
+	links, err := readLibraryLinks(libsFile, []string{"Math=0x00000000000000000000000000000000000000cc"})
+	assert.Nil(t, err)
+	assert.Equal(t, common.HexToAddress("0xcc"), links["Math"])
+	assert.Equal(t, common.HexToAddress("0xaa"), links["Strings"])
+
+	for _, link := range []string{"Math", "=0x00000000000000000000000000000000000000cc", "Math=0x01"} {
+		_, err := readLibraryLinks("", []string{link})
+		assert.NotNil(t, err, link)
+	}
+}
+
+func Test_Abigen_DeployLibs(t *testing.T) {
+	artifact := &contractArtifact{ContractName: "Vault", ABI: []byte(testLinkedABI), Bytecode: testLinkedBytecode}
+	out := filepath.Join(t.TempDir(), "vault.go")
+
+	c := &abigen{fOutFile: out, fLinks: []string{"Strings=0x00000000000000000000000000000000000000aa"}}
+	err := c.generate(artifact)
+	assert.ErrorContains(t, err, "Vault references libraries without an address: __$6ad30996409d058139477db06ae39abaac$__")
+
+	c.fDeployLibs = true
+	assert.Nil(t, c.generate(artifact))
+	code, err := os.ReadFile(out)
+	assert.Nil(t, err)
+	assert.Contains(t, string(code), "func DeployVaultWithLibraries(auth *bind.TransactOpts, backend bind.ContractBackend, libraries VaultLibraries, owner common.Address, amount *big.Int)")
+	assert.Contains(t, string(code), "Library6ad30996 common.Address // __$6ad30996409d058139477db06ae39abaac$__")
+	assert.NotContains(t, string(code), "__Strings")
+	// the plain deploy would send the bytecode with its placeholders
+	assert.Contains(t, string(code), `errors.New("Vault links libraries on deployment, please use DeployVaultWithLibraries")`)
+	assert.Equal(t, 1, strings.Count(string(code), "bind.DeployContract("))
+
+	// every library is linked, there is nothing left to deploy with
+	c.fLinks = append(c.fLinks, "contracts/Math.sol:Math=0x00000000000000000000000000000000000000bb")
+	assert.NotNil(t, c.generate(artifact))
+}
+
+func Test_Abigen_Dir(t *testing.T) {
+	infoABI := `[{"type":"function","name":"info","inputs":[],"outputs":[{"name":"","type":"tuple","internalType":"struct Info","components":[{"name":"owner","type":"address"},{"name":"supply","type":"uint256"}]}],"stateMutability":"view"}]`
+	dir := t.TempDir()
+	writeArtifact := func(name string, v any) {
+		data, err := json.Marshal(v)
+		assert.Nil(t, err)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), data, 0600))
+	}
+	for _, source := range []string{"contracts/Token", "contracts/TokenFactory", "test/TokenTest"} {
+		name := filepath.Base(source)
+		writeArtifact(source+".sol/"+name+".json", map[string]any{
+			"_format": "hh-sol-artifact-1", "contractName": name, "sourceName": source + ".sol",
+			"abi": json.RawMessage(infoABI), "bytecode": "0x6080", "deployedBytecode": "0x6080",
+		})
+		writeArtifact(source+".sol/"+name+".dbg.json", map[string]any{"_format": "hh-sol-dbg-1", "buildInfo": "../../build-info/1.json"})
+	}
+	writeArtifact("contracts/Vault.sol/Vault.json", map[string]any{
+		"_format": "hh-sol-artifact-1", "contractName": "Vault", "sourceName": "contracts/Vault.sol",
+		"abi": json.RawMessage(testLinkedABI), "bytecode": testLinkedBytecode, "deployedBytecode": "0x6080",
+	})
+	writeArtifact("build-info/1.json", map[string]any{"_format": "hh-sol-build-info-1", "input": map[string]any{}, "output": map[string]any{}})
+
+	// a package per contract, the contracts of --include but not of --exclude, and Vault without its libraries
+	out := t.TempDir()
+	c := &abigen{fDir: dir, fInclude: []string{"Token*", "Vault"}, fExclude: []string{"*Test*"}, fOutDir: out}
+	summary, err := c.generateDir()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, summary.Generated)
+	assert.Equal(t, 1, summary.Skipped)
+	assert.Equal(t, 1, summary.Excluded)
+	code, err := os.ReadFile(filepath.Join(out, "tokenfactory", "tokenfactory.go"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(code), "package tokenfactory")
+	assert.FileExists(t, filepath.Join(out, "token", "token.go"))
+	assert.Contains(t, summary.String(), "skipped    Vault references libraries without an address")
+	assert.Contains(t, summary.String(), "=> 2 generated, 0 unchanged, 1 skipped, 1 excluded by --include/--exclude")
+
+	// the files already hold the bindings
+	summary, err = c.generateDir()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, summary.Generated)
+	assert.Equal(t, 2, summary.Unchanged)
+
+	// a single package declares the struct shared by both contracts once
+	c = &abigen{fDir: dir, fInclude: []string{"Token*"}, fExclude: []string{"*Test*"}, fOutDir: out, fPkg: "contracts"}
+	summary, err = c.generateDir()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, summary.Generated)
+	code, err = os.ReadFile(filepath.Join(out, "contracts.go"))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, strings.Count(string(code), "type Info struct"))
+	assert.Contains(t, string(code), "type TokenFactory struct")
+
+	c = &abigen{fDir: dir, fInclude: []string{"[Token"}, fOutDir: out}
+	_, err = c.generateDir()
+	assert.ErrorContains(t, err, "invalid pattern")
+}
+
+func Test_Abigen_TS(t *testing.T) {
+	eventABI := strings.TrimSuffix(testLinkedABI, "]") + `,{"type":"event","name":"Deposit","inputs":[{"name":"owner","type":"address","indexed":true},{"name":"memo","type":"string","indexed":true},{"name":"amount","type":"uint256","indexed":false}],"anonymous":false}]`
+	artifact := &contractArtifact{ContractName: "Vault", ABI: []byte(eventABI), Bytecode: "0x6080", DeployedBytecode: "0x6081"}
+	out := filepath.Join(t.TempDir(), "Vault.ts")
+
+	c := &abigen{fOutFile: out, fLang: abigenLangTS, fIncludeDeployed: true}
+	assert.Nil(t, c.generate(artifact))
+	code, err := os.ReadFile(out)
+	assert.Nil(t, err)
+	assert.Contains(t, string(code), "export const VaultAbi = [\n  {\n    \"type\": \"constructor\",")
+	assert.Contains(t, string(code), "] as const\n")
+	assert.Contains(t, string(code), "export const VaultBytecode: Hex = '0x6080'")
+	assert.Contains(t, string(code), "export const VaultDeployedBytecode: Hex = '0x6081'")
+	assert.Contains(t, string(code), "export type VaultConstructorArgs = readonly [owner: Address, amount: bigint]")
+	assert.Contains(t, string(code), "  /** total(), selector 0x2ddbd13a */\n  total: { inputs: readonly []; outputs: readonly [bigint]; stateMutability: 'view' }")
+	// the indexed string is only known by its hash
+	assert.Contains(t, string(code), "  Deposit: { owner: Address; memo: Hex; amount: bigint }")
+
+	// a module per contract in --out-dir
+	dir := filepath.Dir(writeJSONFile(t, "Vault.json", map[string]any{
+		"_format": "hh-sol-artifact-1", "contractName": "Vault", "sourceName": "contracts/Vault.sol",
+		"abi": json.RawMessage(eventABI), "bytecode": "0x6080", "deployedBytecode": "0x6081",
+	}))
+	outDir := t.TempDir()
+	c = &abigen{fDir: dir, fOutDir: outDir, fLang: abigenLangTS}
+	summary, err := c.generateDir()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, summary.Generated)
+	assert.FileExists(t, filepath.Join(outDir, "Vault.ts"))
+}
+
+func Test_TSType(t *testing.T) {
+	for _, tc := range []struct {
+		abiType string
+		tsType  string
+	}{
+		{"uint48", "number"},
+		{"int256", "bigint"},
+		{"bytes32", "Hex"},
+		{"address[2]", "readonly Address[]"},
+		{"string[][]", "readonly (readonly string[])[]"},
+	} {
+		typ, err := abi.NewType(tc.abiType, "", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.tsType, tsType(typ), tc.abiType)
+	}
+}
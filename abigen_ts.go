@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/0xsequence/ethkit/go-ethereum/accounts/abi"
+)
+
+// abigen target languages
+const (
+	abigenLangGo = "go"
+	abigenLangTS = "ts"
+)
+
+var abigenLanguages = []string{abigenLangGo, abigenLangTS}
+
+// tsReservedWords are the reserved words of TypeScript which can not label the elements of a tuple type.
+var tsReservedWords = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true, "debugger": true,
+	"default": true, "delete": true, "do": true, "else": true, "enum": true, "export": true, "extends": true,
+	"false": true, "finally": true, "for": true, "function": true, "if": true, "import": true, "in": true,
+	"instanceof": true, "new": true, "null": true, "return": true, "super": true, "switch": true, "this": true,
+	"throw": true, "true": true, "try": true, "typeof": true, "var": true, "void": true, "while": true, "with": true,
+}
+
+var tsTemplate = template.Must(template.New("").Parse(`// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+export type Address = ` + "`0x${string}`" + `
+export type Hex = ` + "`0x${string}`" + `
+{{range .}}
+// {{.Type}}Abi is the ABI of {{.Type}}, typed as a literal for the libraries inferring types from it, like viem.
+export const {{.Type}}Abi = {{.ABI}} as const
+{{if .Bytecode}}
+// {{.Type}}Bytecode is the bytecode deploying {{.Type}}.
+export const {{.Type}}Bytecode: Hex = '{{.Bytecode}}'
+{{end}}{{if .DeployedBytecode}}
+// {{.Type}}DeployedBytecode is the runtime bytecode of {{.Type}}.
+export const {{.Type}}DeployedBytecode: Hex = '{{.DeployedBytecode}}'
+{{end}}
+// {{.Type}}ConstructorArgs are the arguments of the constructor of {{.Type}}.
+export type {{.Type}}ConstructorArgs = readonly [{{.Constructor}}]
+
+// {{.Type}}Functions are the functions of {{.Type}}, with the types of their arguments and results.
+export interface {{.Type}}Functions {
+{{- range .Functions}}
+  /** {{.Signature}}, selector {{.Selector}} */
+  {{.Name}}: { inputs: readonly [{{.Inputs}}]; outputs: readonly [{{.Outputs}}]; stateMutability: '{{.StateMutability}}' }
+{{- end}}
+}
+
+// {{.Type}}Events are the events of {{.Type}}, with the types of their fields. The indexed strings, bytes, arrays
+// and structs are only known by the hash of their topic.
+export interface {{.Type}}Events {
+{{- range .Events}}
+  /** {{.Signature}}{{if .Topic}}, topic {{.Topic}}{{end}} */
+  {{.Name}}: { {{.Fields}} }
+{{- end}}
+}
+{{end}}`))
+
+type tsContract struct {
+	Type                       string
+	ABI                        string
+	Bytecode, DeployedBytecode string
+	Constructor                string
+	Functions                  []tsFunction
+	Events                     []tsEvent
+}
+
+type tsFunction struct {
+	Name, Signature, Selector string
+	Inputs, Outputs           string
+	StateMutability           string
+}
+
+type tsEvent struct {
+	Name, Signature, Topic, Fields string
+}
+
+// bindTS generates a TypeScript module of contracts, declaring for each its ABI as a const literal, its bytecode,
+// and the types of the arguments and results of its functions and of the fields of its events.
+func bindTS(contracts []*abigenContract) (string, error) {
+	data := make([]*tsContract, 0, len(contracts))
+	for _, contract := range contracts {
+		parsed, err := abi.JSON(bytes.NewReader(contract.artifact.ABI))
+		if err != nil {
+			return "", fmt.Errorf("error: invalid abi of %s: %w", contract.typeName, err)
+		}
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, bytes.TrimSpace(contract.artifact.ABI), "", "  "); err != nil {
+			return "", err
+		}
+
+		c := &tsContract{
+			Type:             abi.ToCamelCase(contract.typeName),
+			ABI:              indented.String(),
+			Bytecode:         contract.bytecode,
+			DeployedBytecode: contract.deployedBytecode,
+			Constructor:      tsArguments(parsed.Constructor.Inputs, "arg", true),
+		}
+
+		names := make([]string, 0, len(parsed.Methods))
+		for name := range parsed.Methods {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			method := parsed.Methods[name]
+			c.Functions = append(c.Functions, tsFunction{
+				Name:            method.Name,
+				Signature:       method.Sig,
+				Selector:        "0x" + hex.EncodeToString(method.ID),
+				Inputs:          tsArguments(method.Inputs, "arg", true),
+				Outputs:         tsArguments(method.Outputs, "", false),
+				StateMutability: tsStateMutability(method),
+			})
+		}
+
+		names = names[:0]
+		for name := range parsed.Events {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			event := parsed.Events[name]
+			e := tsEvent{Name: event.Name, Signature: event.Sig}
+			if !event.Anonymous {
+				e.Topic = event.ID.Hex()
+			}
+			fields := make([]string, len(event.Inputs))
+			for i, input := range event.Inputs {
+				fields[i] = fmt.Sprintf("%s: %s", tsArgumentName(input.Name, "arg", i), tsEventFieldType(input))
+			}
+			e.Fields = strings.Join(fields, "; ")
+			c.Events = append(c.Events, e)
+		}
+
+		data = append(data, c)
+	}
+
+	var buf bytes.Buffer
+	if err := tsTemplate.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// tsArguments returns the elements of the tuple type of arguments, labeled by their names. Unnamed arguments are
+// labeled after their position, unless label is false and the tuple is then left unlabeled, as TypeScript requires
+// every element of a tuple or none to be labeled.
+func tsArguments(args abi.Arguments, prefix string, label bool) string {
+	elems := make([]string, len(args))
+	for i, arg := range args {
+		if arg.Name == "" && !label {
+			for i, arg := range args {
+				elems[i] = tsType(arg.Type)
+			}
+			return strings.Join(elems, ", ")
+		}
+		elems[i] = fmt.Sprintf("%s: %s", tsArgumentName(arg.Name, prefix, i), tsType(arg.Type))
+	}
+	return strings.Join(elems, ", ")
+}
+
+// tsArgumentName returns the label of an argument, named after its position when unnamed.
+func tsArgumentName(name, prefix string, i int) string {
+	switch {
+	case name == "":
+		return fmt.Sprintf("%s%d", prefix, i)
+	case tsReservedWords[name]:
+		return name + "_"
+	}
+	return name
+}
+
+// tsType returns the TypeScript type of an ABI type, the integers of up to 48 bits being numbers and the larger
+// ones bigints, as decoded by viem and ethers.
+func tsType(t abi.Type) string {
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		if t.Size <= 48 {
+			return "number"
+		}
+		return "bigint"
+	case abi.BoolTy:
+		return "boolean"
+	case abi.StringTy:
+		return "string"
+	case abi.AddressTy:
+		return "Address"
+	case abi.SliceTy, abi.ArrayTy:
+		elem := tsType(*t.Elem)
+		if strings.HasPrefix(elem, "readonly ") {
+			elem = "(" + elem + ")"
+		}
+		return fmt.Sprintf("readonly %s[]", elem)
+	case abi.TupleTy:
+		fields := make([]string, len(t.TupleElems))
+		// the abi parser requires every field of a tuple to be named
+		for i, elem := range t.TupleElems {
+			fields[i] = fmt.Sprintf("%s: %s", t.TupleRawNames[i], tsType(*elem))
+		}
+		return fmt.Sprintf("{ %s }", strings.Join(fields, "; "))
+	}
+	// bytes, fixed bytes, hashes and function pointers
+	return "Hex"
+}
+
+// tsEventFieldType returns the type of an event field, the hash of its topic for indexed dynamic values.
+func tsEventFieldType(arg abi.Argument) string {
+	if arg.Indexed {
+		switch arg.Type.T {
+		case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+			return "Hex"
+		}
+	}
+	return tsType(arg.Type)
+}
+
+// tsStateMutability returns the state mutability of a method, derived from the legacy constant and payable flags
+// of the ABIs of solc < 0.6.
+func tsStateMutability(method abi.Method) string {
+	switch {
+	case method.StateMutability != "":
+		return method.StateMutability
+	case method.Constant:
+		return "view"
+	case method.Payable:
+		return "payable"
+	}
+	return "nonpayable"
+}
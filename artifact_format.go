@@ -0,0 +1,390 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/0xsequence/ethkit/go-ethereum/accounts/abi"
+)
+
+// artifact formats
+const (
+	artifactFormatTruffle          = "truffle"
+	artifactFormatHardhat          = "hardhat"
+	artifactFormatFoundry          = "foundry"
+	artifactFormatSolcCombined     = "solc-combined"
+	artifactFormatSolcStandardJSON = "solc-standard-json"
+)
+
+var artifactFormats = []string{artifactFormatTruffle, artifactFormatHardhat, artifactFormatFoundry, artifactFormatSolcCombined, artifactFormatSolcStandardJSON}
+
+// errUnknownArtifactsFormat is returned for a JSON file which is not an artifacts file of a known format.
+var errUnknownArtifactsFormat = errors.New("unknown artifacts format")
+
+// contractArtifact is a compiled contract, normalized from any of the artifact formats.
+type contractArtifact struct {
+	ContractName           string
+	SourceName             string // the source file of the contract, when known
+	ABI                    json.RawMessage
+	Bytecode               string // the 0x-prefixed creation bytecode, which may hold library placeholders
+	DeployedBytecode       string // the 0x-prefixed runtime bytecode, which may hold library placeholders
+	LinkReferences         linkReferences
+	DeployedLinkReferences linkReferences
+	MethodIdentifiers      map[string]string // the 4-byte hex selectors of the method signatures
+	Metadata               string            // the raw solc metadata JSON, when known
+}
+
+// linkReferences are the positions of the library addresses in a bytecode, by source file and library name.
+type linkReferences map[string]map[string][]struct {
+	Start  int `json:"start"`
+	Length int `json:"length"`
+}
+
+// libraryNames returns the fully qualified library names, e.g. contracts/Math.sol:Math, of the placeholders found
+// at the link references of a bytecode.
+func (refs linkReferences) libraryNames(bytecode string) map[string]string {
+	names := map[string]string{}
+	code := strings.TrimPrefix(bytecode, "0x")
+	for source, libraries := range refs {
+		for library, positions := range libraries {
+			for _, pos := range positions {
+				if 2*pos.Start+libraryPlaceholderLength <= len(code) {
+					names[code[2*pos.Start:2*pos.Start+libraryPlaceholderLength]] = source + ":" + library
+				}
+			}
+		}
+	}
+	return names
+}
+
+// FullName returns the fully qualified name of the contract when its source file is known, e.g. contracts/Foo.sol:Foo.
+func (a *contractArtifact) FullName() string {
+	if a.SourceName == "" {
+		return a.ContractName
+	}
+	return a.SourceName + ":" + a.ContractName
+}
+
+// libraryNames returns the fully qualified library names of the placeholders of both bytecodes.
+func (a *contractArtifact) libraryNames() map[string]string {
+	names := a.LinkReferences.libraryNames(a.Bytecode)
+	for placeholder, name := range a.DeployedLinkReferences.libraryNames(a.DeployedBytecode) {
+		names[placeholder] = name
+	}
+	return names
+}
+
+// normalize prefixes the bytecodes with 0x, and computes the method identifiers from the abi for the formats
+// without them.
+func (a *contractArtifact) normalize() error {
+	for _, code := range []*string{&a.Bytecode, &a.DeployedBytecode} {
+		if *code = strings.TrimPrefix(strings.TrimSpace(*code), "0x"); *code != "" {
+			*code = "0x" + *code
+		}
+	}
+
+	if len(a.ABI) == 0 {
+		return fmt.Errorf("error: no abi found for contract %s", a.FullName())
+	}
+	if a.MethodIdentifiers == nil {
+		parsed, err := abi.JSON(bytes.NewReader(a.ABI))
+		if err != nil {
+			return fmt.Errorf("error: unable to parse the abi of contract %s: %w", a.FullName(), err)
+		}
+		a.MethodIdentifiers = make(map[string]string, len(parsed.Methods))
+		for _, method := range parsed.Methods {
+			a.MethodIdentifiers[method.Sig] = hex.EncodeToString(method.ID)
+		}
+	}
+	return nil
+}
+
+// readArtifacts reads the contracts of an artifacts file of a format, detected from its content when empty.
+func readArtifacts(path, format string) ([]*contractArtifact, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("error: %s is not a JSON artifacts file: %w", path, err)
+	}
+	if format == "" {
+		if format = detectArtifactFormat(fields); format == "" {
+			return nil, fmt.Errorf("error: %w of %s, please pass --format %s", errUnknownArtifactsFormat, path, strings.Join(artifactFormats, "|"))
+		}
+	}
+
+	var contracts []*contractArtifact
+	switch format {
+	case artifactFormatTruffle:
+		contracts, err = parseTruffleArtifact(data)
+	case artifactFormatHardhat:
+		contracts, err = parseHardhatArtifact(data)
+	case artifactFormatFoundry:
+		contracts, err = parseFoundryArtifact(path, data)
+	case artifactFormatSolcCombined:
+		contracts, err = parseSolcCombinedJSON(data)
+	case artifactFormatSolcStandardJSON:
+		contracts, err = parseSolcStandardJSON(data)
+	default:
+		return nil, fmt.Errorf("error: unknown --format %q, supported: %s", format, strings.Join(artifactFormats, ", "))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error: invalid %s artifacts file %s: %w", format, path, err)
+	}
+
+	for _, c := range contracts {
+		if err := c.normalize(); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(contracts, func(i, j int) bool { return contracts[i].FullName() < contracts[j].FullName() })
+	return contracts, nil
+}
+
+// readArtifactFile reads a contract of an artifacts file, the only contract of the file unless named.
+func readArtifactFile(path, format, contract string) (*contractArtifact, error) {
+	contracts, err := readArtifacts(path, format)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(contracts))
+	for i, c := range contracts {
+		names[i] = c.FullName()
+		if contract != "" && (contract == c.ContractName || contract == c.FullName()) {
+			return c, nil
+		}
+	}
+	switch {
+	case len(contracts) == 0:
+		return nil, fmt.Errorf("error: no contract found in %s", path)
+	case contract != "":
+		return nil, fmt.Errorf("error: no contract %s in %s, found: %s", contract, path, strings.Join(names, ", "))
+	case len(contracts) > 1:
+		return nil, fmt.Errorf("error: %s holds %d contracts, please pass --contract with one of: %s", path, len(contracts), strings.Join(names, ", "))
+	}
+	return contracts[0], nil
+}
+
+// detectArtifactFormat returns the format of an artifacts file from its top-level fields, or an empty string.
+func detectArtifactFormat(fields map[string]json.RawMessage) string {
+	var hardhatFormat string
+	if json.Unmarshal(fields["_format"], &hardhatFormat) == nil && strings.HasPrefix(hardhatFormat, "hh-sol-artifact") {
+		return artifactFormatHardhat
+	}
+
+	if bytecode, ok := fields["bytecode"]; ok {
+		if bytes.HasPrefix(bytes.TrimSpace(bytecode), []byte("{")) {
+			return artifactFormatFoundry
+		}
+		return artifactFormatTruffle
+	}
+
+	// both solc outputs have a contracts field, by fully qualified name or by source file and name
+	var contracts map[string]map[string]json.RawMessage
+	if json.Unmarshal(fields["contracts"], &contracts) != nil || len(contracts) == 0 {
+		return ""
+	}
+	for _, contract := range contracts {
+		if _, ok := contract["bin"]; ok {
+			return artifactFormatSolcCombined
+		}
+		if _, ok := contract["abi"]; ok {
+			return artifactFormatSolcCombined
+		}
+	}
+	return artifactFormatSolcStandardJSON
+}
+
+// parseTruffleArtifact parses a truffle artifact, whose bytecode holds legacy __Name____ library placeholders. The
+// source name is the sourcePath of the contract, the path of its file when compiled.
+func parseTruffleArtifact(data []byte) ([]*contractArtifact, error) {
+	var artifact struct {
+		ContractName     string          `json:"contractName"`
+		SourcePath       string          `json:"sourcePath"`
+		ABI              json.RawMessage `json:"abi"`
+		Bytecode         string          `json:"bytecode"`
+		DeployedBytecode string          `json:"deployedBytecode"`
+		Metadata         string          `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &artifact); err != nil {
+		return nil, err
+	}
+	return []*contractArtifact{{
+		ContractName:     artifact.ContractName,
+		SourceName:       artifact.SourcePath,
+		ABI:              artifact.ABI,
+		Bytecode:         artifact.Bytecode,
+		DeployedBytecode: artifact.DeployedBytecode,
+		Metadata:         artifact.Metadata,
+	}}, nil
+}
+
+// parseHardhatArtifact parses a hardhat artifacts/<source>/<Name>.json artifact, whose metadata is only kept in
+// the build info.
+func parseHardhatArtifact(data []byte) ([]*contractArtifact, error) {
+	var artifact struct {
+		ContractName           string          `json:"contractName"`
+		SourceName             string          `json:"sourceName"`
+		ABI                    json.RawMessage `json:"abi"`
+		Bytecode               string          `json:"bytecode"`
+		DeployedBytecode       string          `json:"deployedBytecode"`
+		LinkReferences         linkReferences  `json:"linkReferences"`
+		DeployedLinkReferences linkReferences  `json:"deployedLinkReferences"`
+	}
+	if err := json.Unmarshal(data, &artifact); err != nil {
+		return nil, err
+	}
+	return []*contractArtifact{{
+		ContractName:           artifact.ContractName,
+		SourceName:             artifact.SourceName,
+		ABI:                    artifact.ABI,
+		Bytecode:               artifact.Bytecode,
+		DeployedBytecode:       artifact.DeployedBytecode,
+		LinkReferences:         artifact.LinkReferences,
+		DeployedLinkReferences: artifact.DeployedLinkReferences,
+	}}, nil
+}
+
+// evmBytecode is the bytecode object of the foundry and solc standard JSON outputs.
+type evmBytecode struct {
+	Object         string         `json:"object"`
+	LinkReferences linkReferences `json:"linkReferences"`
+}
+
+// parseFoundryArtifact parses a foundry out/<Source>.sol/<Name>.json artifact. The contract is named by the
+// compilation target of its metadata, or else by the name of the file.
+func parseFoundryArtifact(path string, data []byte) ([]*contractArtifact, error) {
+	var artifact struct {
+		ABI               json.RawMessage   `json:"abi"`
+		Bytecode          evmBytecode       `json:"bytecode"`
+		DeployedBytecode  evmBytecode       `json:"deployedBytecode"`
+		MethodIdentifiers map[string]string `json:"methodIdentifiers"`
+		Metadata          json.RawMessage   `json:"metadata"`
+		RawMetadata       string            `json:"rawMetadata"`
+	}
+	if err := json.Unmarshal(data, &artifact); err != nil {
+		return nil, err
+	}
+
+	contract := &contractArtifact{
+		ContractName:           strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		ABI:                    artifact.ABI,
+		Bytecode:               artifact.Bytecode.Object,
+		DeployedBytecode:       artifact.DeployedBytecode.Object,
+		LinkReferences:         artifact.Bytecode.LinkReferences,
+		DeployedLinkReferences: artifact.DeployedBytecode.LinkReferences,
+		MethodIdentifiers:      artifact.MethodIdentifiers,
+		Metadata:               artifact.RawMetadata,
+	}
+	if contract.Metadata == "" && len(artifact.Metadata) > 0 && string(artifact.Metadata) != "null" {
+		contract.Metadata = string(artifact.Metadata)
+	}
+
+	var metadata struct {
+		Settings struct {
+			CompilationTarget map[string]string `json:"compilationTarget"`
+		} `json:"settings"`
+	}
+	if json.Unmarshal([]byte(contract.Metadata), &metadata) == nil {
+		for source, name := range metadata.Settings.CompilationTarget {
+			contract.SourceName, contract.ContractName = source, name
+		}
+	}
+	return []*contractArtifact{contract}, nil
+}
+
+// parseSolcCombinedJSON parses the output of solc --combined-json, e.g. abi,bin,bin-runtime,hashes,metadata, whose
+// abi is a JSON string before solc 0.8.10. Its bytecodes have no link references, nor a 0x prefix.
+func parseSolcCombinedJSON(data []byte) ([]*contractArtifact, error) {
+	var output struct {
+		Contracts map[string]struct {
+			ABI        json.RawMessage   `json:"abi"`
+			Bin        string            `json:"bin"`
+			BinRuntime string            `json:"bin-runtime"`
+			Hashes     map[string]string `json:"hashes"`
+			Metadata   string            `json:"metadata"`
+		} `json:"contracts"`
+	}
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, err
+	}
+
+	contracts := []*contractArtifact{}
+	for name, c := range output.Contracts {
+		source, contractName, ok := strings.Cut(name, ":")
+		if !ok {
+			return nil, fmt.Errorf("contract %q is not named source:Name", name)
+		}
+		contractABI := c.ABI
+		var s string
+		if json.Unmarshal(c.ABI, &s) == nil {
+			contractABI = json.RawMessage(s)
+		}
+		contracts = append(contracts, &contractArtifact{
+			ContractName:      contractName,
+			SourceName:        source,
+			ABI:               contractABI,
+			Bytecode:          c.Bin,
+			DeployedBytecode:  c.BinRuntime,
+			MethodIdentifiers: c.Hashes,
+			Metadata:          c.Metadata,
+		})
+	}
+	return contracts, nil
+}
+
+// parseSolcStandardJSON parses the output of solc --standard-json, the contracts of every source.
+func parseSolcStandardJSON(data []byte) ([]*contractArtifact, error) {
+	var output struct {
+		Errors []struct {
+			Severity         string `json:"severity"`
+			FormattedMessage string `json:"formattedMessage"`
+		} `json:"errors"`
+		Contracts map[string]map[string]struct {
+			ABI      json.RawMessage `json:"abi"`
+			Metadata string          `json:"metadata"`
+			EVM      struct {
+				Bytecode          evmBytecode       `json:"bytecode"`
+				DeployedBytecode  evmBytecode       `json:"deployedBytecode"`
+				MethodIdentifiers map[string]string `json:"methodIdentifiers"`
+			} `json:"evm"`
+		} `json:"contracts"`
+	}
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, err
+	}
+	for _, e := range output.Errors {
+		if e.Severity == "error" {
+			return nil, errors.New("the compilation failed: " + strings.TrimSpace(e.FormattedMessage))
+		}
+	}
+
+	contracts := []*contractArtifact{}
+	for source, sourceContracts := range output.Contracts {
+		for name, c := range sourceContracts {
+			contracts = append(contracts, &contractArtifact{
+				ContractName:           name,
+				SourceName:             source,
+				ABI:                    c.ABI,
+				Bytecode:               c.EVM.Bytecode.Object,
+				DeployedBytecode:       c.EVM.DeployedBytecode.Object,
+				LinkReferences:         c.EVM.Bytecode.LinkReferences,
+				DeployedLinkReferences: c.EVM.DeployedBytecode.LinkReferences,
+				MethodIdentifiers:      c.EVM.MethodIdentifiers,
+				Metadata:               c.Metadata,
+			})
+		}
+	}
+	return contracts, nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testMathLinkReferences are the positions of the contracts/Math.sol:Math placeholders of testLinkedBytecode.
+var testMathLinkReferences = map[string]any{
+	"contracts/Math.sol": map[string]any{"Math": []map[string]int{{"start": 6, "length": 20}, {"start": 50, "length": 20}}},
+}
+
+func writeJSONFile(t *testing.T, name string, v any) string {
+	data, err := json.Marshal(v)
+	assert.Nil(t, err)
+	path := filepath.Join(t.TempDir(), name)
+	assert.Nil(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+func Test_ReadArtifacts(t *testing.T) {
+	for _, tc := range []struct {
+		format string
+		path   string
+	}{
+		{artifactFormatTruffle, writeJSONFile(t, "Vault.json", map[string]any{
+			"contractName":     "Vault",
+			"sourcePath":       "contracts/Vault.sol",
+			"abi":              json.RawMessage(testLinkedABI),
+			"bytecode":         testLinkedBytecode,
+			"deployedBytecode": "0x6080",
+			"metadata":         `{"compiler":{"version":"0.8.20"}}`,
+		})},
+		{artifactFormatHardhat, writeJSONFile(t, "Vault.json", map[string]any{
+			"_format":                "hh-sol-artifact-1",
+			"contractName":           "Vault",
+			"sourceName":             "contracts/Vault.sol",
+			"abi":                    json.RawMessage(testLinkedABI),
+			"bytecode":               testLinkedBytecode,
+			"deployedBytecode":       "0x6080",
+			"linkReferences":         testMathLinkReferences,
+			"deployedLinkReferences": map[string]any{},
+		})},
+		{artifactFormatFoundry, writeJSONFile(t, "Vault.json", map[string]any{
+			"abi":               json.RawMessage(testLinkedABI),
+			"bytecode":          map[string]any{"object": testLinkedBytecode, "linkReferences": testMathLinkReferences},
+			"deployedBytecode":  map[string]any{"object": "0x6080", "linkReferences": map[string]any{}},
+			"methodIdentifiers": map[string]string{"total()": "2ddbd13a"},
+			"metadata":          map[string]any{"settings": map[string]any{"compilationTarget": map[string]string{"src/Vault.sol": "Vault"}}},
+		})},
+		{artifactFormatSolcCombined, writeJSONFile(t, "combined.json", map[string]any{
+			"contracts": map[string]any{
+				"contracts/Vault.sol:Vault": map[string]any{"abi": json.RawMessage(testLinkedABI), "bin": strings.TrimPrefix(testLinkedBytecode, "0x"), "bin-runtime": "6080", "hashes": map[string]string{"total()": "2ddbd13a"}},
+				// the abi is a JSON string before solc 0.8.10
+				"contracts/ERC20.sol:ERC20": map[string]any{"abi": erc20ABI, "bin": "", "bin-runtime": ""},
+			},
+			"version": "0.8.20",
+		})},
+		{artifactFormatSolcStandardJSON, writeJSONFile(t, "output.json", map[string]any{
+			"contracts": map[string]any{
+				"contracts/Vault.sol": map[string]any{"Vault": map[string]any{
+					"abi":      json.RawMessage(testLinkedABI),
+					"metadata": `{"compiler":{"version":"0.8.20"}}`,
+					"evm": map[string]any{
+						"bytecode":          map[string]any{"object": strings.TrimPrefix(testLinkedBytecode, "0x"), "linkReferences": testMathLinkReferences},
+						"deployedBytecode":  map[string]any{"object": "6080", "linkReferences": map[string]any{}},
+						"methodIdentifiers": map[string]string{"total()": "2ddbd13a"},
+					},
+				}},
+				"contracts/ERC20.sol": map[string]any{"ERC20": map[string]any{"abi": json.RawMessage(erc20ABI)}},
+			},
+			"sources": map[string]any{},
+		})},
+	} {
+		contracts, err := readArtifacts(tc.path, "")
+		assert.Nil(t, err, tc.format)
+
+		var fields map[string]json.RawMessage
+		data, _ := os.ReadFile(tc.path)
+		assert.Nil(t, json.Unmarshal(data, &fields))
+		assert.Equal(t, tc.format, detectArtifactFormat(fields))
+
+		vault, err := readArtifactFile(tc.path, tc.format, "Vault")
+		assert.Nil(t, err, tc.format)
+		assert.Equal(t, "Vault", vault.ContractName, tc.format)
+
+		// every format names the source of the contract, which --contract also matches
+		assert.True(t, strings.HasSuffix(vault.SourceName, "/Vault.sol"), tc.format)
+		_, err = readArtifactFile(tc.path, tc.format, vault.FullName())
+		assert.Nil(t, err, tc.format)
+		assert.Equal(t, testLinkedBytecode, vault.Bytecode, tc.format)
+		assert.Equal(t, "0x6080", vault.DeployedBytecode, tc.format)
+		assert.Equal(t, map[string]string{"total()": "2ddbd13a"}, vault.MethodIdentifiers, tc.format)
+
+		switch tc.format {
+		case artifactFormatHardhat, artifactFormatFoundry, artifactFormatSolcStandardJSON:
+			placeholder := "__$" + libraryPlaceholderHash("contracts/Math.sol:Math") + "$__"
+			assert.Equal(t, map[string]string{placeholder: "contracts/Math.sol:Math"}, vault.libraryNames(), tc.format)
+		}
+
+		if len(contracts) > 1 {
+			_, err := readArtifactFile(tc.path, tc.format, "")
+			assert.ErrorContains(t, err, "please pass --contract with one of: contracts/ERC20.sol:ERC20, contracts/Vault.sol:Vault")
+
+			erc20, err := readArtifactFile(tc.path, tc.format, "contracts/ERC20.sol:ERC20")
+			assert.Nil(t, err)
+			assert.Equal(t, "", erc20.Bytecode)
+			assert.Equal(t, "a9059cbb", erc20.MethodIdentifiers["transfer(address,uint256)"])
+		}
+	}
+
+	path := writeJSONFile(t, "unknown.json", map[string]any{"name": "Vault"})
+	_, err := readArtifacts(path, "")
+	assert.ErrorContains(t, err, "please pass --format")
+	_, err = readArtifacts(path, "brownie")
+	assert.NotNil(t, err)
+}
+
+func Test_Abigen_LinkReferences(t *testing.T) {
+	path := writeJSONFile(t, "Vault.json", map[string]any{
+		"_format":          "hh-sol-artifact-1",
+		"contractName":     "Vault",
+		"sourceName":       "contracts/Vault.sol",
+		"abi":              json.RawMessage(testLinkedABI),
+		"bytecode":         testLinkedBytecode,
+		"deployedBytecode": "0x6080",
+		"linkReferences":   testMathLinkReferences,
+	})
+	artifact, err := readArtifactFile(path, "", "")
+	assert.Nil(t, err)
+
+	// the link references name the library of the placeholder, which is linked by its name
+	out := filepath.Join(t.TempDir(), "vault.go")
+	c := &abigen{fOutFile: out, fLinks: []string{"Math=0x00000000000000000000000000000000000000bb", "Strings=0x00000000000000000000000000000000000000aa"}}
+	assert.Nil(t, c.generate(artifact))
+	code, err := os.ReadFile(out)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(code), "__")
+
+	c = &abigen{fOutFile: out, fLinks: []string{"Strings=0x00000000000000000000000000000000000000aa"}, fDeployLibs: true}
+	assert.Nil(t, c.generate(artifact))
+	code, err = os.ReadFile(out)
+	assert.Nil(t, err)
+	assert.Contains(t, string(code), "Math common.Address // contracts/Math.sol:Math")
+}
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 
-	"github.com/0xsequence/ethkit/ethartifact"
 	"github.com/spf13/cobra"
 )
 
@@ -12,13 +14,18 @@ func init() {
 	artifacts := &artifacts{}
 	cmd := &cobra.Command{
 		Use:   "artifacts",
-		Short: "Print the contract abi or bytecode from a truffle artifacts file",
+		Short: "Print the contract abi, bytecode, method identifiers or metadata from a truffle, hardhat, foundry or solc artifacts file",
 		Run:   artifacts.Run,
 	}
 
-	cmd.Flags().String("file", "", "path to truffle contract artifacts file (required)")
+	cmd.Flags().String("file", "", "path to contract artifacts file (required)")
+	cmd.Flags().String("format", "", "artifacts file format: truffle, hardhat, foundry, solc-combined or solc-standard-json, default: detected")
+	cmd.Flags().String("contract", "", "the contract of an artifacts file holding several, as Name or path/Foo.sol:Name")
 	cmd.Flags().Bool("abi", false, "abi")
 	cmd.Flags().Bool("bytecode", false, "bytecode")
+	cmd.Flags().Bool("deployed-bytecode", false, "deployed bytecode")
+	cmd.Flags().Bool("method-identifiers", false, "method identifiers, the 4-byte selectors of the method signatures")
+	cmd.Flags().Bool("metadata", false, "solc metadata")
 
 	rootCmd.AddCommand(cmd)
 }
@@ -28,36 +35,86 @@ type artifacts struct {
 
 func (c *artifacts) Run(cmd *cobra.Command, args []string) {
 	fFile, _ := cmd.Flags().GetString("file")
+	fFormat, _ := cmd.Flags().GetString("format")
+	fContract, _ := cmd.Flags().GetString("contract")
 	fAbi, _ := cmd.Flags().GetBool("abi")
 	fBytecode, _ := cmd.Flags().GetBool("bytecode")
+	fDeployedBytecode, _ := cmd.Flags().GetBool("deployed-bytecode")
+	fMethodIdentifiers, _ := cmd.Flags().GetBool("method-identifiers")
+	fMetadata, _ := cmd.Flags().GetBool("metadata")
 
 	if fFile == "" {
 		fmt.Println("error: please pass --file")
 		help(cmd)
 		return
 	}
-	if !fAbi && !fBytecode {
-		fmt.Println("error: please pass either --abi or --bytecode")
-		help(cmd)
-		return
+	set := 0
+	for _, f := range []bool{fAbi, fBytecode, fDeployedBytecode, fMethodIdentifiers, fMetadata} {
+		if f {
+			set++
+		}
 	}
-	if fAbi && fBytecode {
-		fmt.Println("error: please pass either --abi or --bytecode, not both")
+	if set != 1 {
+		fmt.Println("error: please pass one of --abi, --bytecode, --deployed-bytecode, --method-identifiers or --metadata")
 		help(cmd)
 		return
 	}
 
-	artifacts, err := ethartifact.ParseArtifactFile(fFile)
+	artifact, err := readArtifactFile(fFile, fFormat, fContract)
 	if err != nil {
 		log.Fatal(err)
 		return
 	}
 
-	if fAbi {
-		fmt.Println(string(artifacts.ABI))
+	res := &Artifact{ContractName: artifact.ContractName}
+	switch {
+	case fAbi:
+		res.ABI = artifact.ABI
+	case fBytecode:
+		res.Bytecode = artifact.Bytecode
+	case fDeployedBytecode:
+		res.DeployedBytecode = artifact.DeployedBytecode
+	case fMethodIdentifiers:
+		res.MethodIdentifiers = artifact.MethodIdentifiers
+	case fMetadata:
+		if artifact.Metadata == "" {
+			log.Fatalf("error: %s holds no metadata of contract %s", fFile, artifact.FullName())
+		}
+		res.Metadata = json.RawMessage(artifact.Metadata)
+	}
+
+	if err := printResult(cmd, res); err != nil {
+		log.Fatal(err)
 	}
+}
+
+// Artifact is the abi, a bytecode, the method identifiers or the metadata of a contract artifacts file.
+type Artifact struct {
+	ContractName      string            `json:"contractName"`
+	ABI               json.RawMessage   `json:"abi,omitempty"`
+	Bytecode          string            `json:"bytecode,omitempty"`
+	DeployedBytecode  string            `json:"deployedBytecode,omitempty"`
+	MethodIdentifiers map[string]string `json:"methodIdentifiers,omitempty"`
+	Metadata          json.RawMessage   `json:"metadata,omitempty"`
+}
 
-	if fBytecode {
-		fmt.Println(artifacts.Bytecode)
+// String overrides the standard behavior for Artifact "to-string", printing the raw abi, bytecode or metadata,
+// or a selector and signature per line.
+func (a *Artifact) String() string {
+	switch {
+	case a.ABI != nil:
+		return string(a.ABI)
+	case a.Metadata != nil:
+		return string(a.Metadata)
+	case a.MethodIdentifiers != nil:
+		lines := make([]string, 0, len(a.MethodIdentifiers))
+		for sig, selector := range a.MethodIdentifiers {
+			lines = append(lines, "0x"+selector+"  "+sig)
+		}
+		sort.Strings(lines)
+		return strings.Join(lines, "\n")
+	case a.DeployedBytecode != "":
+		return a.DeployedBytecode
 	}
+	return a.Bytecode
 }
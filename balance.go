@@ -5,12 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
-	"net/url"
-	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 
-	"github.com/0xsequence/ethkit/ethrpc"
 	"github.com/0xsequence/ethkit/go-ethereum/common"
 	"github.com/0xsequence/ethkit/go-ethereum/params"
 )
@@ -18,7 +16,6 @@ import (
 const (
 	flagBalanceBlock = "block"
 	flagBalanceEther = "ether"
-	flagBalanceRpcUrl = "rpc-url"
 )
 
 func init() {
@@ -35,9 +32,9 @@ func NewBalanceCmd() *cobra.Command {
 		RunE:  c.Run,
 	}
 
-	cmd.Flags().StringP(flagBalanceBlock, "B", "latest", "The block height to query at")
-	cmd.Flags().BoolP(flagBalanceEther, "e", false, "Format the balance in ether")
-	cmd.Flags().StringP(flagBalanceRpcUrl, "r", "", "The RPC endpoint to the blockchain node to interact with")
+	cmd.Flags().StringP(flagBalanceBlock, "B", "latest", "The block number, hash or tag to query at (e.g. 123, 0x7b, latest, finalized, latest-10)")
+	cmd.Flags().BoolP(flagBalanceEther, "e", false, "Format the balance in the native currency of the network, ether by default")
+	addRpcFlags(cmd)
 
 	return cmd
 }
@@ -55,55 +52,62 @@ func (c *balance) Run(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	fRpc, err := cmd.Flags().GetString(flagBalanceRpcUrl)
-	if err != nil {
-		return err
-	}
 
 	if !common.IsHexAddress(fAccount) {
 		return errors.New("error: please provide a valid account address (e.g. 0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742)")
 	}
 
-	if _, err = url.ParseRequestURI(fRpc); err != nil {
-		return errors.New("error: please provide a valid rpc url (e.g. https://nodes.sequence.app/mainnet)")
-	}
-
-	provider, err := ethrpc.NewProvider(fRpc)
+	provider, network, err := newProvider(cmd)
 	if err != nil {
 		return err
 	}
 
-	block, err := strconv.ParseUint(fBlock, 10, 64)
+	block, err := parseBlockRef(fBlock)
 	if err != nil {
-		// TODO: implement support for all tags: earliest, latest, pending, finalized, safe
-		if fBlock == "latest" {
-			bh, err := provider.BlockNumber(context.Background())
-			if err != nil {
-				return err
-			}
-			block = bh
-		} else {
-			return errors.New("error: invalid block height")
-		}
+		return err
 	}
 
-	wei, err := provider.BalanceAt(context.Background(), common.HexToAddress(fAccount), big.NewInt(int64(block)))
+	wei, err := balanceAt(context.Background(), provider, common.HexToAddress(fAccount), block)
 	if err != nil {
 		return err
 	}
 
-	if fEther {
-		bal := weiToEther(wei)
-		fmt.Fprintln(cmd.OutOrStdout(), bal, "ether")
-	} else {
-		fmt.Fprintln(cmd.OutOrStdout(), wei, "wei")
-	}
+	symbol, decimals := network.currency()
+	return printResult(cmd, &Balance{
+		Account:  common.HexToAddress(fAccount),
+		Block:    block.String(),
+		Wei:      wei,
+		Ether:    weiToUnits(wei, decimals).Text('f', -1),
+		Currency: symbol,
+		inEther:  fEther,
+		network:  network,
+	})
+}
 
-	return nil
+// Balance is the balance of an account at a block, in wei and in the native currency of the network.
+type Balance struct {
+	Account  common.Address `json:"account"`
+	Block    string         `json:"block"`
+	Wei      *big.Int       `json:"wei"`
+	Ether    string         `json:"ether"`
+	Currency string         `json:"currency"`
+
+	inEther bool
+	network *network
 }
 
+// String overrides the standard behavior for Balance "to-string", printing the balance in wei or, with --ether, in
+// the native currency of the network.
+func (b *Balance) String() string {
+	if b.inEther {
+		return b.network.formatAmount(b.Wei)
+	}
+	return fmt.Sprintf("%v wei", b.Wei)
+}
+
+// weiToUnits converts an amount of wei into units of a currency of a number of decimals, 18 for ether.
 // https://github.com/ethereum/go-ethereum/issues/21221
-func weiToEther(wei *big.Int) *big.Float {
+func weiToUnits(wei *big.Int, decimals uint8) *big.Float {
 	f := new(big.Float)
 	f.SetPrec(236) //  IEEE 754 octuple-precision binary floating-point format: binary256
 	f.SetMode(big.ToNearestEven)
@@ -111,5 +115,40 @@ func weiToEther(wei *big.Int) *big.Float {
 	fWei.SetPrec(236) //  IEEE 754 octuple-precision binary floating-point format: binary256
 	fWei.SetMode(big.ToNearestEven)
 
-	return f.Quo(fWei.SetInt(wei), big.NewFloat(params.Ether))
+	unit := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	return f.Quo(fWei.SetInt(wei), unit)
+}
+
+// parseEtherValue parses an amount with an optional unit suffix (wei, gwei or ether) into wei,
+// e.g. 0.1ether, 30gwei or 1000. Amounts without a unit are in wei.
+func parseEtherValue(s string) (*big.Int, error) {
+	amount := strings.ToLower(strings.TrimSpace(s))
+	unit := big.NewInt(params.Wei)
+
+	for _, u := range []struct {
+		suffix string
+		value  float64
+	}{
+		{"gwei", params.GWei},
+		{"ether", params.Ether},
+		{"eth", params.Ether},
+		{"wei", params.Wei},
+	} {
+		if strings.HasSuffix(amount, u.suffix) {
+			amount = strings.TrimSpace(strings.TrimSuffix(amount, u.suffix))
+			unit, _ = big.NewFloat(u.value).Int(nil)
+			break
+		}
+	}
+
+	r, ok := new(big.Rat).SetString(amount)
+	if !ok || r.Sign() < 0 {
+		return nil, fmt.Errorf("error: invalid amount %q (e.g. 0.1ether, 30gwei, 1000wei)", s)
+	}
+	r.Mul(r, new(big.Rat).SetInt(unit))
+	if !r.IsInt() {
+		return nil, fmt.Errorf("error: amount %q is not a whole number of wei", s)
+	}
+
+	return r.Num(), nil
 }
@@ -9,6 +9,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/0xsequence/ethkit-cli/internal/rpctest"
 )
 
 func execBalanceCmd(args string) (string, error) {
@@ -25,23 +27,38 @@ func execBalanceCmd(args string) (string, error) {
 }
 
 func Test_BalanceCmd(t *testing.T) {
-	res, err := execBalanceCmd("0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742 --rpc-url https://nodes.sequence.app/sepolia")
+	srv := rpctest.NewServer(t, "testdata/rpc/balance.json")
+	res, err := execBalanceCmd("0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742 --rpc-url " + srv.URL)
 	assert.Nil(t, err)
 	assert.NotNil(t, res)
 }
 
 func Test_BalanceCmd_ValidWei(t *testing.T) {
-	res, err := execBalanceCmd("0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742 --rpc-url https://nodes.sequence.app/sepolia")
+	srv := rpctest.NewServer(t, "testdata/rpc/balance.json")
+	res, err := execBalanceCmd("0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742 --rpc-url " + srv.URL)
 	assert.Nil(t, err)
 	assert.Equal(t, res, fmt.Sprintln(strconv.Itoa(500_000_000_000_000_000), "wei"))
 }
 
 func Test_BalanceCmd_ValidEther(t *testing.T) {
-	res, err := execBalanceCmd("0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742 --rpc-url https://nodes.sequence.app/sepolia --ether")
+	srv := rpctest.NewServer(t, "testdata/rpc/balance.json")
+	res, err := execBalanceCmd("0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742 --rpc-url " + srv.URL + " --ether")
 	assert.Nil(t, err)
 	assert.Equal(t, res, fmt.Sprintln(strconv.FormatFloat(0.5, 'f', -1, 64), "ether"))
 }
 
+func Test_BalanceCmd_NetworkCurrency(t *testing.T) {
+	srv := rpctest.NewServer(t, "testdata/rpc/balance.json")
+	setupConfig(t)
+	cfg := &config{Current: "polygon"}
+	cfg.addNetwork(&network{Name: "polygon", URL: srv.URL, Currency: "POL", Decimals: 18})
+	assert.Nil(t, cfg.save())
+
+	res, err := execBalanceCmd("0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742 --ether")
+	assert.Nil(t, err)
+	assert.Equal(t, "0.5 POL\n", res)
+}
+
 func Test_BalanceCmd_InvalidAddress(t *testing.T) {
 	res, err := execBalanceCmd("0x1 --rpc-url https://nodes.sequence.app/sepolia")
 	assert.NotNil(t, err)
@@ -57,7 +74,8 @@ func Test_BalanceCmd_InvalidRPC(t *testing.T) {
 }
 
 func Test_BalanceCmd_NotExistingBlockHeigh(t *testing.T) {
-	res, err := execBalanceCmd("0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742 --rpc-url https://nodes.sequence.app/sepolia --block " + fmt.Sprint(math.MaxInt64))
+	srv := rpctest.NewServer(t, "testdata/rpc/balance.json")
+	res, err := execBalanceCmd("0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742 --rpc-url " + srv.URL + " --block " + fmt.Sprint(math.MaxInt64))
 	assert.NotNil(t, err)
 	assert.Empty(t, res)
 	assert.Contains(t, err.Error(), "jsonrpc error -32000: header not found")
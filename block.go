@@ -2,23 +2,19 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"math/big"
-	"net/url"
-	"strconv"
 
 	"github.com/spf13/cobra"
 
-	"github.com/0xsequence/ethkit/ethrpc"
 	"github.com/0xsequence/ethkit/go-ethereum/common"
-	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
 	"github.com/0xsequence/ethkit/go-ethereum/core/types"
 )
 
 const (
 	flagBlockField = "field"
+	flagBlockFields = "fields"
 	flagBlockFull = "full"
-	flagBlockRpcUrl = "rpc-url"
 	flagBlockJson = "json"
 )
 
@@ -40,10 +36,11 @@ func NewBlockCmd() *cobra.Command {
 		RunE:    c.Run,
 	}
 
-	cmd.Flags().StringP(flagBlockField, "f", "", "Get the specific field of a block")
+	cmd.Flags().StringP(flagBlockField, "f", "", "Get a field by its path, e.g. hash, transactions[0].hash, uncles.length (comma-separated for multiple fields)")
+	cmd.Flags().String(flagBlockFields, "", "Comma-separated list of the fields to print, in order (e.g. number,hash,timestamp)")
 	cmd.Flags().Bool(flagBlockFull, false, "Get the full block information")
-	cmd.Flags().StringP(flagBlockRpcUrl, "r", "", "The RPC endpoint to the blockchain node to interact with")
-	cmd.Flags().BoolP(flagBlockJson, "j", false, "Print the block as JSON")
+	addRpcFlags(cmd)
+	cmd.Flags().BoolP(flagBlockJson, "j", false, "Print as JSON, alias of --output json")
 
 	return cmd
 }
@@ -54,45 +51,32 @@ func (c *block) Run(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	fFull, err := cmd.Flags().GetBool(flagBlockFull)
+	fFields, err := cmd.Flags().GetString(flagBlockFields)
 	if err != nil {
 		return err
 	}
-	fRpc, err := cmd.Flags().GetString(flagBlockRpcUrl)
+	fFull, err := cmd.Flags().GetBool(flagBlockFull)
 	if err != nil {
 		return err
 	}
-	fJson, err := cmd.Flags().GetBool(flagBlockJson)
-	if err != nil {
-		return err
+
+	if fField != "" && fFields != "" {
+		return errors.New("error: --field and --fields cannot be used together")
 	}
 
-	if _, err = url.ParseRequestURI(fRpc); err != nil {
-		return ErrInvalidRpcUrl
+	provider, _, err := newProvider(cmd)
+	if err != nil {
+		return err
 	}
 
-	provider, err := ethrpc.NewProvider(fRpc)
+	ref, err := parseBlockRef(fBlock)
 	if err != nil {
 		return err
 	}
 
-	var block *types.Block
-	if _, err := hexutil.Decode(fBlock); err == nil {
-		block, err = provider.BlockByHash(context.Background(), common.HexToHash(fBlock))
-		if err != nil {
-			return ErrBlockNotFound
-		}
-	} else {
-		bh, err := strconv.ParseUint(fBlock, 10, 64)
-		if err != nil {
-			// TODO: implement support for all tags: earliest, latest, pending, finalized, safe
-			return ErrInvalidBlockInfo
-		}
-	
-		block, err = provider.BlockByNumber(context.Background(), big.NewInt(int64(bh)))
-		if err != nil {
-			return ErrBlockNotFound
-		}
+	block, err := blockByRef(context.Background(), provider, ref)
+	if err != nil {
+		return err
 	}
 
 	var obj any
@@ -103,20 +87,18 @@ func (c *block) Run(cmd *cobra.Command, args []string) error {
 	}
 
 	if fField != "" {
-		obj = GetValueByJSONTag(obj, fField)
+		if obj, err = GetValueByJSONTag(obj, fField); err != nil {
+			return err
+		}
 	}
 
-	if fJson {
-		json, err := PrettyJSON(obj)
-		if err != nil {
+	if fFields != "" {
+		if obj, err = selectFields(obj, fFields); err != nil {
 			return err
 		}
-		obj = *json
 	}
 
-	fmt.Fprintln(cmd.OutOrStdout(), obj)
-
-	return nil
+	return printResult(cmd, obj)
 }
 
 // Header is a customized block header for cli.
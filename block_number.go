@@ -2,17 +2,9 @@ package main
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"net/url"
 
 	"github.com/spf13/cobra"
-
-	"github.com/0xsequence/ethkit/ethrpc"
-)
-
-const (
-	flagBlockNumberRpcUrl = "rpc-url"
 )
 
 func init() {
@@ -29,7 +21,7 @@ func NewBlockNumberCmd() *cobra.Command {
 		RunE:    c.Run,
 	}
 
-	cmd.Flags().StringP(flagBlockNumberRpcUrl, "r", "", "The RPC endpoint to the blockchain node to interact with")
+	addRpcFlags(cmd)
 
 	return cmd
 }
@@ -38,16 +30,7 @@ type blockNumber struct {
 }
 
 func (c *blockNumber) Run(cmd *cobra.Command, args []string) error {
-	fRpc, err := cmd.Flags().GetString(flagBalanceRpcUrl)
-	if err != nil {
-		return err
-	}
-
-	if _, err = url.ParseRequestURI(fRpc); err != nil {
-		return errors.New("error: please provide a valid rpc url (e.g. https://nodes.sequence.app/mainnet)")
-	}
-
-	provider, err := ethrpc.NewProvider(fRpc)
+	provider, _, err := newProvider(cmd)
 	if err != nil {
 		return err
 	}
@@ -57,7 +40,15 @@ func (c *blockNumber) Run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	fmt.Fprintln(cmd.OutOrStdout(), bh)
+	return printResult(cmd, &BlockNumber{Number: bh})
+}
+
+// BlockNumber is the latest block number of a network.
+type BlockNumber struct {
+	Number uint64 `json:"blockNumber"`
+}
 
-	return nil
+// String overrides the standard behavior for BlockNumber "to-string".
+func (b *BlockNumber) String() string {
+	return fmt.Sprint(b.Number)
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	app "github.com/0xsequence/ethkit-cli"
+	"github.com/0xsequence/ethkit-cli/internal/rpctest"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -24,9 +25,10 @@ func execBlockNumberCmd(args string) (string, error) {
 }
 
 func Test_BlockNumberCmd(t *testing.T) {
-	res, err := execBlockNumberCmd("--rpc-url https://nodes.sequence.app/sepolia")
+	srv := rpctest.NewServer(t, "testdata/rpc/block_number.json")
+	res, err := execBlockNumberCmd("--rpc-url " + srv.URL)
 	assert.Nil(t, err)
-	assert.NotNil(t, res)
+	assert.Equal(t, "5269665\n", res)
 }
 
 func Test_BlockNumberCmd_InvalidRPC(t *testing.T) {
@@ -8,6 +8,8 @@ import (
 
 	"github.com/0xsequence/ethkit/go-ethereum/common/math"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/0xsequence/ethkit-cli/internal/rpctest"
 )
 
 func execBlockCmd(args string) (string, error) {
@@ -24,13 +26,15 @@ func execBlockCmd(args string) (string, error) {
 }
 
 func Test_BlockCmd_ValidBlockHeight(t *testing.T) {
-	res, err := execBlockCmd("18855325 --rpc-url https://nodes.sequence.app/mainnet")
+	srv := rpctest.NewServer(t, "testdata/rpc/block.json")
+	res, err := execBlockCmd("18855325 --rpc-url " + srv.URL)
 	assert.Nil(t, err)
 	assert.NotNil(t, res)
 }
 
 func Test_BlockCmd_ValidBlockHash(t *testing.T) {
-	res, err := execBlockCmd("0x97e5c24dc2fd74f6e56773a0ad1cf29fe403130ca6ec1dd10ff8828d72b0a352 --rpc-url https://nodes.sequence.app/mainnet")
+	srv := rpctest.NewServer(t, "testdata/rpc/block.json")
+	res, err := execBlockCmd("0x97e5c24dc2fd74f6e56773a0ad1cf29fe403130ca6ec1dd10ff8828d72b0a352 --rpc-url " + srv.URL)
 	assert.Nil(t, err)
 	assert.NotNil(t, res)
 }
@@ -41,9 +45,9 @@ func Test_BlockCmd_InvalidRpcUrl(t *testing.T) {
 	assert.Empty(t, res)
 }
 
-// Note: this test will eventually fail
 func Test_BlockCmd_NotFoundByHeight(t *testing.T) {
-	res, err := execBlockCmd(fmt.Sprint(math.MaxInt64) + " --rpc-url https://nodes.sequence.app/mainnet")
+	srv := rpctest.NewServer(t, "testdata/rpc/block.json")
+	res, err := execBlockCmd(fmt.Sprint(math.MaxInt64) + " --rpc-url " + srv.URL)
 	assert.Equal(t, err, ErrBlockNotFound)
 	assert.Empty(t, res)
 }
@@ -55,42 +59,69 @@ func Test_BlockCmd_InvalidBlockHeight(t *testing.T) {
 }
 
 func Test_BlockCmd_NotFoundByHash(t *testing.T) {
-	res, err := execBlockCmd("0x97e5c24dc2fd74f6e56773a0ad1cf29fe403130ca6ec1dd10ff8828d72b0a351 --rpc-url https://nodes.sequence.app/mainnet")
+	srv := rpctest.NewServer(t, "testdata/rpc/block.json")
+	res, err := execBlockCmd("0x97e5c24dc2fd74f6e56773a0ad1cf29fe403130ca6ec1dd10ff8828d72b0a351 --rpc-url " + srv.URL)
 	assert.Equal(t, err, ErrBlockNotFound)
 	assert.Empty(t, res)
 }
 
 func Test_BlockCmd_HeaderValidJSON(t *testing.T) {
-	res, err := execBlockCmd("18855325 --rpc-url https://nodes.sequence.app/mainnet --json")
+	srv := rpctest.NewServer(t, "testdata/rpc/block.json")
+	res, err := execBlockCmd("18855325 --rpc-url " + srv.URL + " --json")
 	assert.Nil(t, err)
 	h := Header{}
 	var p Printable
 	_ = p.FromStruct(h)
-	for k := range p {
+	for _, k := range p.Keys() {
 		assert.Contains(t, res, k)
 	}
 }
 
 func Test_BlockCmd_BlockValidJSON(t *testing.T) {
-	res, err := execBlockCmd("18855325 --rpc-url https://nodes.sequence.app/mainnet --full --json")
+	srv := rpctest.NewServer(t, "testdata/rpc/block.json")
+	res, err := execBlockCmd("18855325 --rpc-url " + srv.URL + " --full --json")
 	assert.Nil(t, err)
 	h := Block{}
 	var p Printable
 	_ = p.FromStruct(h)
-	for k := range p {
+	for _, k := range p.Keys() {
 		assert.Contains(t, res, k)
 	}
 }
 
 func Test_BlockCmd_BlockValidFieldHash(t *testing.T) {
 	// validating also that -f is case-insensitive
-	res, err := execBlockCmd("18855325 --rpc-url https://nodes.sequence.app/mainnet --full -f HASh")
+	srv := rpctest.NewServer(t, "testdata/rpc/block.json")
+	res, err := execBlockCmd("18855325 --rpc-url " + srv.URL + " --full -f HASh")
 	assert.Nil(t, err)
 	assert.Equal(t, res, "0x97e5c24dc2fd74f6e56773a0ad1cf29fe403130ca6ec1dd10ff8828d72b0a352\n")
 }
 
 func Test_BlockCmd_BlockInvalidField(t *testing.T) {
-	res, err := execBlockCmd("18855325 --rpc-url https://nodes.sequence.app/mainnet --full -f invalid")
+	srv := rpctest.NewServer(t, "testdata/rpc/block.json")
+	res, err := execBlockCmd("18855325 --rpc-url " + srv.URL + " --full -f invalid")
+	assert.ErrorContains(t, err, `field "invalid" not found`)
+	assert.Empty(t, res)
+}
+
+func Test_BlockCmd_FieldPaths(t *testing.T) {
+	srv := rpctest.NewServer(t, "testdata/rpc/block.json")
+	res, err := execBlockCmd("18855325 --rpc-url " + srv.URL + " --full -f transactions[0].hash")
+	assert.Nil(t, err)
+	assert.Equal(t, "0xdeaca0c85cb4784430f6cea150c67e3ff7dc199b787ff57a30d41a0f376919fe\n", res)
+
+	res, err = execBlockCmd("18855325 --rpc-url " + srv.URL + " --full -f withdrawals[*].amount")
+	assert.Nil(t, err)
+	assert.Equal(t, "0x10c8e0f\n0x10d1a2b\n", res)
+
+	res, err = execBlockCmd("18855325 --rpc-url " + srv.URL + " -f transactions.length")
+	assert.Nil(t, err)
+	assert.Equal(t, "2\n", res)
+}
+
+func Test_BlockCmd_Fields(t *testing.T) {
+	srv := rpctest.NewServer(t, "testdata/rpc/block.json")
+	res, err := execBlockCmd("18855325 --rpc-url " + srv.URL + " --fields number,hash --json")
 	assert.Nil(t, err)
-	assert.Equal(t, res, "<nil>\n")
+	assert.Equal(t, "{\n  \"number\": 18855325,\n  \"hash\": \"0x97e5c24dc2fd74f6e56773a0ad1cf29fe403130ca6ec1dd10ff8828d72b0a352\"\n}\n", res)
 }
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+)
+
+// Standard JSON-RPC block tags.
+const (
+	blockTagEarliest  = "earliest"
+	blockTagLatest    = "latest"
+	blockTagPending   = "pending"
+	blockTagSafe      = "safe"
+	blockTagFinalized = "finalized"
+)
+
+var blockTags = []string{blockTagEarliest, blockTagLatest, blockTagPending, blockTagSafe, blockTagFinalized}
+
+// blockRef is a reference to a block, either by number, by hash or by tag.
+// A tag can be followed by a relative offset, e.g. latest-10.
+type blockRef struct {
+	Tag    string
+	Offset uint64
+	Number *big.Int
+	Hash   *common.Hash
+}
+
+// parseBlockRef parses a block reference. Accepted formats are decimal numbers (123),
+// hex numbers (0x7b), 32-byte hashes, the standard tags (earliest, latest, pending, safe, finalized)
+// and tags with a relative offset (latest-10).
+func parseBlockRef(s string) (*blockRef, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, ErrInvalidBlockInfo
+	}
+
+	if has0xPrefix(s) {
+		if len(s) == 2+2*common.HashLength {
+			b, err := hexutil.Decode(s)
+			if err != nil {
+				return nil, ErrInvalidBlockInfo
+			}
+			h := common.BytesToHash(b)
+			return &blockRef{Hash: &h}, nil
+		}
+		n, err := hexutil.DecodeBig(s)
+		if err != nil {
+			return nil, ErrInvalidBlockInfo
+		}
+		return &blockRef{Number: n}, nil
+	}
+
+	if n, ok := new(big.Int).SetString(s, 10); ok {
+		if n.Sign() < 0 {
+			return nil, ErrInvalidBlockInfo
+		}
+		return &blockRef{Number: n}, nil
+	}
+
+	tag, offset, hasOffset := strings.Cut(strings.ToLower(s), "-")
+	if !isBlockTag(tag) {
+		return nil, ErrInvalidBlockInfo
+	}
+	ref := &blockRef{Tag: tag}
+	if hasOffset {
+		o, err := strconv.ParseUint(offset, 10, 64)
+		if err != nil {
+			return nil, ErrInvalidBlockInfo
+		}
+		if tag == blockTagEarliest && o > 0 {
+			return nil, ErrInvalidBlockInfo
+		}
+		ref.Offset = o
+	}
+
+	return ref, nil
+}
+
+func isBlockTag(s string) bool {
+	for _, tag := range blockTags {
+		if s == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func has0xPrefix(s string) bool {
+	return len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X')
+}
+
+// String returns the block reference in the format accepted by parseBlockRef.
+func (b *blockRef) String() string {
+	switch {
+	case b.Hash != nil:
+		return b.Hash.Hex()
+	case b.Number != nil:
+		return b.Number.String()
+	case b.Offset > 0:
+		return fmt.Sprintf("%s-%d", b.Tag, b.Offset)
+	default:
+		return b.Tag
+	}
+}
+
+// resolve converts a tag with a relative offset into an absolute block number.
+// Any other reference is returned unchanged.
+func (b *blockRef) resolve(ctx context.Context, provider *ethrpc.Provider) (*blockRef, error) {
+	if b.Offset == 0 {
+		return b, nil
+	}
+
+	var head *types.Header
+	call := ethrpc.NewCallBuilder[*types.Header]("eth_getBlockByNumber", nil, b.Tag, false).Into(&head)
+	if _, err := provider.Do(ctx, call); err != nil {
+		return nil, err
+	}
+	if head == nil {
+		return nil, ErrBlockNotFound
+	}
+
+	offset := new(big.Int).SetUint64(b.Offset)
+	if head.Number.Cmp(offset) < 0 {
+		return nil, fmt.Errorf("error: offset %d is beyond the %s block %s", b.Offset, b.Tag, head.Number)
+	}
+
+	return &blockRef{Number: new(big.Int).Sub(head.Number, offset)}, nil
+}
+
+// numberArg returns the JSON-RPC block number parameter (a tag or a hex quantity).
+// It must not be called on hash references or on unresolved relative references.
+func (b *blockRef) numberArg() string {
+	if b.Number != nil {
+		return hexutil.EncodeBig(b.Number)
+	}
+	return b.Tag
+}
+
+// param returns the JSON-RPC block parameter for state queries such as eth_getBalance
+// and eth_call, using the EIP-1898 object form for hashes.
+func (b *blockRef) param() any {
+	if b.Hash != nil {
+		return map[string]any{"blockHash": *b.Hash}
+	}
+	return b.numberArg()
+}
+
+// blockByRef retrieves a block by any kind of block reference.
+func blockByRef(ctx context.Context, provider *ethrpc.Provider, ref *blockRef) (*types.Block, error) {
+	ref, err := ref.resolve(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	var block *types.Block
+	var call ethrpc.Call
+	if ref.Hash != nil {
+		call = ethrpc.BlockByHash(*ref.Hash).Into(&block)
+	} else {
+		call = ethrpc.NewCallBuilder[*types.Block]("eth_getBlockByNumber", ethrpc.IntoBlock, ref.numberArg(), true).Into(&block)
+	}
+	if _, err := provider.Do(ctx, call); err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return nil, ErrBlockNotFound
+		}
+		return nil, err
+	}
+	if block == nil {
+		return nil, ErrBlockNotFound
+	}
+
+	return block, nil
+}
+
+// balanceAt retrieves the balance of an account at any kind of block reference.
+func balanceAt(ctx context.Context, provider *ethrpc.Provider, account common.Address, ref *blockRef) (*big.Int, error) {
+	ref, err := ref.resolve(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret *hexutil.Big
+	call := ethrpc.NewCallBuilder[*hexutil.Big]("eth_getBalance", nil, account, ref.param()).Into(&ret)
+	if _, err := provider.Do(ctx, call); err != nil {
+		return nil, err
+	}
+	if ret == nil {
+		return nil, errors.New("error: empty balance response")
+	}
+
+	return ret.ToInt(), nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseBlockRef(t *testing.T) {
+	hash := "0x97e5c24dc2fd74f6e56773a0ad1cf29fe403130ca6ec1dd10ff8828d72b0a352"
+
+	tests := []struct {
+		in     string
+		ref    blockRef
+		numArg string
+	}{
+		{in: "18855325", ref: blockRef{Number: big.NewInt(18855325)}, numArg: "0x11fb59d"},
+		{in: "0x11fb59d", ref: blockRef{Number: big.NewInt(18855325)}, numArg: "0x11fb59d"},
+		{in: "0", ref: blockRef{Number: big.NewInt(0)}, numArg: "0x0"},
+		{in: "earliest", ref: blockRef{Tag: blockTagEarliest}, numArg: "earliest"},
+		{in: "latest", ref: blockRef{Tag: blockTagLatest}, numArg: "latest"},
+		{in: "Pending", ref: blockRef{Tag: blockTagPending}, numArg: "pending"},
+		{in: "safe", ref: blockRef{Tag: blockTagSafe}, numArg: "safe"},
+		{in: "finalized", ref: blockRef{Tag: blockTagFinalized}, numArg: "finalized"},
+		{in: "latest-10", ref: blockRef{Tag: blockTagLatest, Offset: 10}},
+		{in: "finalized-0", ref: blockRef{Tag: blockTagFinalized}, numArg: "finalized"},
+	}
+
+	for _, tt := range tests {
+		ref, err := parseBlockRef(tt.in)
+		assert.Nil(t, err, tt.in)
+		assert.Equal(t, tt.ref, *ref, tt.in)
+		if tt.numArg != "" {
+			assert.Equal(t, tt.numArg, ref.numberArg(), tt.in)
+		}
+	}
+
+	ref, err := parseBlockRef(hash)
+	assert.Nil(t, err)
+	assert.Equal(t, common.HexToHash(hash), *ref.Hash)
+	assert.Equal(t, map[string]any{"blockHash": common.HexToHash(hash)}, ref.param())
+	assert.Equal(t, hash, ref.String())
+}
+
+func Test_ParseBlockRef_Invalid(t *testing.T) {
+	for _, in := range []string{"", "something", "-100", "0xzz", "latest-", "latest-abc", "earliest-1", "latest+1", "0x97e5c24dc2fd74f6e56773a0ad1cf29fe403130ca6ec1dd10ff8828d72b0a3zz"} {
+		_, err := parseBlockRef(in)
+		assert.Equal(t, ErrInvalidBlockInfo, err, in)
+	}
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/0xsequence/ethkit/ethcoder"
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum/accounts/abi"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+)
+
+const (
+	flagCallBlock = "block"
+	flagCallAbi   = "abi"
+	flagCallData  = "data"
+	flagCallFrom  = "from"
+	flagCallJson  = "json"
+)
+
+func init() {
+	rootCmd.AddCommand(NewCallCmd())
+}
+
+type call struct {
+}
+
+// NewCallCmd returns a new command to run a read-only contract call.
+func NewCallCmd() *cobra.Command {
+	c := &call{}
+	cmd := &cobra.Command{
+		Use:   "call [address] [signature|method] [args...]",
+		Short: "Call a contract method without sending a transaction",
+		Long: "Call a contract method without sending a transaction (eth_call).\n\n" +
+			"The method is either a human-readable signature with optional return types, e.g. \"balanceOf(address)(uint256)\",\n" +
+			"or the name or signature of a method of the abi passed with --abi. Raw calldata can be passed with --data instead.",
+		Example: `  ethkit call 0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238 "balanceOf(address)(uint256)" 0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742
+  ethkit call 0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238 balanceOf 0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742 --abi ERC20.json`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: c.Run,
+	}
+
+	cmd.Flags().StringP(flagCallBlock, "B", "latest", "The block number, hash or tag to query at")
+	cmd.Flags().String(flagCallAbi, "", "path to a contract artifacts or abi json file")
+	addABIFlags(cmd.Flags())
+	cmd.Flags().String(flagCallData, "", "raw 0x-prefixed calldata, used instead of a method and its arguments")
+	cmd.Flags().String(flagCallFrom, "", "The address the call is made from")
+	addRpcFlags(cmd)
+	cmd.Flags().BoolP(flagCallJson, "j", false, "Print as JSON, alias of --output json")
+
+	return cmd
+}
+
+func (c *call) Run(cmd *cobra.Command, args []string) error {
+	fBlock, err := cmd.Flags().GetString(flagCallBlock)
+	if err != nil {
+		return err
+	}
+	abiSrc, err := abiSourceFor(cmd, flagCallAbi)
+	if err != nil {
+		return err
+	}
+	fData, err := cmd.Flags().GetString(flagCallData)
+	if err != nil {
+		return err
+	}
+	fFrom, err := cmd.Flags().GetString(flagCallFrom)
+	if err != nil {
+		return err
+	}
+
+	if !common.IsHexAddress(args[0]) {
+		return errors.New("error: please provide a valid contract address")
+	}
+	to := common.HexToAddress(args[0])
+
+	var from *common.Address
+	if fFrom != "" {
+		if !common.IsHexAddress(fFrom) {
+			return errors.New("error: please provide a valid --from address")
+		}
+		addr := common.HexToAddress(fFrom)
+		from = &addr
+	}
+
+	var method *abi.Method
+	if len(args) > 1 {
+		method, err = resolveMethod(args[1], abiSrc)
+		if err != nil {
+			return err
+		}
+	}
+
+	var data []byte
+	if fData != "" {
+		if len(args) > 2 {
+			return errors.New("error: method arguments cannot be passed along with --data")
+		}
+		data, err = hexutil.Decode(fData)
+		if err != nil {
+			return errors.New("error: please provide a valid 0x-prefixed hex --data")
+		}
+	} else {
+		if method == nil {
+			return errors.New("error: please provide a method signature or --data")
+		}
+		data, err = encodeCalldata(method, args[2:])
+		if err != nil {
+			return err
+		}
+	}
+
+	ref, err := parseBlockRef(fBlock)
+	if err != nil {
+		return err
+	}
+
+	provider, _, err := newProvider(cmd)
+	if err != nil {
+		return err
+	}
+
+	ret, err := callAt(context.Background(), provider, from, to, data, ref)
+	if err != nil {
+		return err
+	}
+
+	res := &CallResult{
+		To:     to,
+		Block:  ref.String(),
+		Data:   data,
+		Result: ret,
+	}
+	if method != nil && len(method.Outputs) > 0 {
+		res.Signature = method.Sig
+		if res.Returns, err = decodeReturns(method, ret); err != nil {
+			return err
+		}
+	}
+
+	return printResult(cmd, res)
+}
+
+// resolveMethod returns the method described by a human-readable signature such as
+// "balanceOf(address)(uint256)" or, when an abi source is provided, the method of the abi
+// matching the given name or signature.
+func resolveMethod(expr string, abiSrc *abiSource) (*abi.Method, error) {
+	if abiSrc != nil {
+		contractABI, err := loadABI(abiSrc)
+		if err != nil {
+			return nil, err
+		}
+		return findMethod(contractABI, expr)
+	}
+
+	methodExpr, returnsExpr, err := splitSignature(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	mabi, name, err := ethcoder.ParseMethodABI(methodExpr, returnsExpr)
+	if err != nil {
+		return nil, fmt.Errorf("error: invalid method signature %q: %w", expr, err)
+	}
+	method := mabi.Methods[name]
+
+	return &method, nil
+}
+
+// findMethod returns the method of an abi matching a name or a signature.
+func findMethod(contractABI abi.ABI, expr string) (*abi.Method, error) {
+	var matches []abi.Method
+	for _, m := range contractABI.Methods {
+		if m.Sig == expr || m.RawName == expr {
+			matches = append(matches, m)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("error: method %q not found in abi", expr)
+	case 1:
+		return &matches[0], nil
+	default:
+		sigs := make([]string, len(matches))
+		for i, m := range matches {
+			sigs[i] = m.Sig
+		}
+		return nil, fmt.Errorf("error: method %q is overloaded, please use one of the signatures: %s", expr, strings.Join(sigs, ", "))
+	}
+}
+
+// splitSignature splits "name(inputs)(outputs)" into the method and returns expressions.
+// The returns part can also be written as "returns (outputs)".
+func splitSignature(sig string) (string, string, error) {
+	sig = strings.TrimSpace(sig)
+	start := strings.Index(sig, "(")
+	if start < 0 {
+		return "", "", fmt.Errorf("error: invalid method signature %q, expected format is name(types)(returnTypes)", sig)
+	}
+
+	depth := 0
+	for i := start; i < len(sig); i++ {
+		switch sig[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				returns := strings.TrimSpace(sig[i+1:])
+				returns = strings.TrimSpace(strings.TrimPrefix(returns, "returns"))
+				return sig[:i+1], returns, nil
+			}
+		}
+	}
+
+	return "", "", fmt.Errorf("error: invalid method signature %q, unbalanced parentheses", sig)
+}
+
+// encodeCalldata packs the selector and the arguments of a method given as strings.
+func encodeCalldata(method *abi.Method, args []string) ([]byte, error) {
+	values, err := parseABIValues(method.Inputs, args)
+	if err != nil {
+		return nil, err
+	}
+
+	packed, err := method.Inputs.Pack(values...)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(append([]byte{}, method.ID...), packed...), nil
+}
+
+// decodeReturns unpacks the return values of a method call.
+func decodeReturns(method *abi.Method, data []byte) ([]*DecodedArg, error) {
+	if len(data) == 0 {
+		return nil, errors.New("error: the call returned no data, the address may not be a contract")
+	}
+
+	values, err := method.Outputs.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("error: unable to decode %s return values: %w", method.Sig, err)
+	}
+
+	returns := make([]*DecodedArg, len(method.Outputs))
+	for i, output := range method.Outputs {
+		returns[i] = &DecodedArg{
+			Name:  output.Name,
+			Type:  output.Type.String(),
+			Value: formatABIValue(output.Type, values[i]),
+		}
+	}
+
+	return returns, nil
+}
+
+// callAt runs eth_call at any kind of block reference.
+func callAt(ctx context.Context, provider *ethrpc.Provider, from *common.Address, to common.Address, data []byte, ref *blockRef) ([]byte, error) {
+	ref, err := ref.resolve(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := map[string]any{
+		"to":   to,
+		"data": hexutil.Bytes(data),
+	}
+	if from != nil {
+		msg["from"] = *from
+	}
+
+	var ret hexutil.Bytes
+	if _, err := provider.Do(ctx, ethrpc.NewCallBuilder[hexutil.Bytes]("eth_call", nil, msg, ref.param()).Into(&ret)); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// CallResult is the result of a read-only contract call.
+type CallResult struct {
+	To        common.Address `json:"to"`
+	Block     string         `json:"block"`
+	Signature string         `json:"signature,omitempty"`
+	Data      hexutil.Bytes  `json:"data"`
+	Result    hexutil.Bytes  `json:"result"`
+	Returns   []*DecodedArg  `json:"returns,omitempty"`
+}
+
+// String overrides the standard behavior for CallResult "to-string", printing one
+// decoded return value per line, or the raw result when it is not decoded.
+func (r *CallResult) String() string {
+	if len(r.Returns) == 0 {
+		return r.Result.String()
+	}
+
+	lines := make([]string, len(r.Returns))
+	for i, ret := range r.Returns {
+		switch v := ret.Value.(type) {
+		case string:
+			lines[i] = v
+		default:
+			b, err := json.Marshal(v)
+			if err != nil {
+				panic(err)
+			}
+			lines[i] = string(b)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/0xsequence/ethkit/go-ethereum/accounts/abi"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/0xsequence/ethkit-cli/internal/rpctest"
+)
+
+func execCallCmd(args string) (string, error) {
+	cmd := NewCallCmd()
+	actual := new(bytes.Buffer)
+	cmd.SetOut(actual)
+	cmd.SetErr(actual)
+	cmd.SetArgs(strings.Split(args, " "))
+	if err := cmd.Execute(); err != nil {
+		return "", err
+	}
+
+	return actual.String(), nil
+}
+
+func Test_SplitSignature(t *testing.T) {
+	m, r, err := splitSignature("balanceOf(address)(uint256)")
+	assert.Nil(t, err)
+	assert.Equal(t, "balanceOf(address)", m)
+	assert.Equal(t, "(uint256)", r)
+
+	m, r, err = splitSignature("getReserves() returns (uint112,uint112,uint32)")
+	assert.Nil(t, err)
+	assert.Equal(t, "getReserves()", m)
+	assert.Equal(t, "(uint112,uint112,uint32)", r)
+
+	m, r, err = splitSignature("totalSupply()")
+	assert.Nil(t, err)
+	assert.Equal(t, "totalSupply()", m)
+	assert.Equal(t, "", r)
+
+	_, _, err = splitSignature("totalSupply")
+	assert.NotNil(t, err)
+	_, _, err = splitSignature("totalSupply(")
+	assert.NotNil(t, err)
+}
+
+func Test_EncodeCalldata(t *testing.T) {
+	method, err := resolveMethod("balanceOf(address)(uint256)", nil)
+	assert.Nil(t, err)
+
+	data, err := encodeCalldata(method, []string{"0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742"})
+	assert.Nil(t, err)
+	assert.Equal(t, "0x70a08231000000000000000000000000213a286a1af3ac010d4f2d66a52deaf762df7742", hexutil.Encode(data))
+
+	_, err = encodeCalldata(method, []string{"0x1"})
+	assert.NotNil(t, err)
+	_, err = encodeCalldata(method, nil)
+	assert.NotNil(t, err)
+}
+
+func Test_EncodeCalldata_FromABI(t *testing.T) {
+	path := writeABIFile(t)
+
+	method, err := resolveMethod("submit", &abiSource{path: path})
+	assert.Nil(t, err)
+	data, err := encodeCalldata(method, []string{`{"id":"0x0100000000000000000000000000000000000000000000000000000000000000","data":"0xcafe"}`})
+	assert.Nil(t, err)
+
+	contractABI, _ := abi.JSON(strings.NewReader(erc20ABI))
+	call, err := decodeCalldata(contractABI, data)
+	assert.Nil(t, err)
+	assert.Equal(t, "0xcafe", call.Args[0].Value.(map[string]any)["data"])
+
+	_, err = resolveMethod("unknown", &abiSource{path: path})
+	assert.NotNil(t, err)
+}
+
+func Test_ParseABIValue(t *testing.T) {
+	tests := []struct {
+		typ string
+		in  string
+		out any
+	}{
+		{"uint256", "1000", big.NewInt(1000)},
+		{"uint256", "0x3e8", big.NewInt(1000)},
+		{"int256", "-5", big.NewInt(-5)},
+		{"uint8", "255", uint8(255)},
+		{"int64", "-9", int64(-9)},
+		{"bool", "true", true},
+		{"string", "hello world", "hello world"},
+		{"address", "0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742", common.HexToAddress("0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742")},
+		{"bytes", "0xcafe", []byte{0xca, 0xfe}},
+		{"bytes2", "0xcafe", [2]byte{0xca, 0xfe}},
+		{"uint256[]", "[1, \"0x2\"]", []*big.Int{big.NewInt(1), big.NewInt(2)}},
+		{"address[2]", `["0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742","0x0000000000000000000000000000000000000001"]`, [2]common.Address{common.HexToAddress("0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742"), common.HexToAddress("0x1")}},
+	}
+
+	for _, tt := range tests {
+		typ, err := abi.NewType(tt.typ, "", nil)
+		assert.Nil(t, err)
+		v, err := parseABIValue(typ, tt.in)
+		assert.Nil(t, err, tt.typ)
+		assert.Equal(t, tt.out, v, tt.typ)
+	}
+
+	for typ, in := range map[string]string{
+		"uint8":      "256",
+		"int8":       "128",
+		"uint256":    "-1",
+		"bool":       "yes",
+		"address":    "0x1",
+		"bytes2":     "0xca",
+		"uint256[2]": "[1]",
+	} {
+		typ, _ := abi.NewType(typ, "", nil)
+		_, err := parseABIValue(typ, in)
+		assert.NotNil(t, err, typ.String())
+	}
+}
+
+func Test_DecodeReturns(t *testing.T) {
+	method, _ := resolveMethod("getReserves()(uint112 reserve0,uint112 reserve1,bool ok)", nil)
+	data, _ := method.Outputs.Pack(big.NewInt(10), big.NewInt(20), true)
+
+	returns, err := decodeReturns(method, data)
+	assert.Nil(t, err)
+	assert.Equal(t, &DecodedArg{Name: "reserve0", Type: "uint112", Value: "10"}, returns[0])
+
+	res := &CallResult{Returns: returns}
+	assert.Equal(t, "10\n20\ntrue", res.String())
+
+	_, err = decodeReturns(method, nil)
+	assert.NotNil(t, err)
+}
+
+func Test_CallCmd_InvalidArgs(t *testing.T) {
+	_, err := execCallCmd("0x1 balanceOf(address)(uint256) 0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742")
+	assert.NotNil(t, err)
+
+	_, err = execCallCmd("0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238")
+	assert.NotNil(t, err)
+
+	_, err = execCallCmd("0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238 totalSupply()(uint256) --block something --rpc-url https://nodes.sequence.app/sepolia")
+	assert.Equal(t, ErrInvalidBlockInfo, err)
+
+	_, err = execCallCmd("0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238 totalSupply()(uint256) --contract ERC20")
+	assert.EqualError(t, err, "error: --contract and --format apply to the artifacts file of --abi")
+}
+
+func Test_CallCmd(t *testing.T) {
+	srv := rpctest.NewServer(t, "testdata/rpc/call.json")
+	res, err := execCallCmd("0xdAC17F958D2ee523a2206206994597C13D831ec7 balanceOf(address)(uint256) 0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742 --rpc-url " + srv.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, "1000000\n", res)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	envConfig = "ETHKIT_CONFIG"
+	envRpcUrl = "ETHKIT_RPC_URL"
+)
+
+// network is a named blockchain network profile stored in the config file.
+type network struct {
+	Name     string `yaml:"name" json:"name"`
+	URL      string `yaml:"url" json:"url"`
+	ChainID  uint64 `yaml:"chainId,omitempty" json:"chainId,omitempty"`
+	Explorer string `yaml:"explorer,omitempty" json:"explorer,omitempty"`
+	Currency string `yaml:"currency,omitempty" json:"currency,omitempty"`
+	Decimals uint8  `yaml:"decimals,omitempty" json:"decimals,omitempty"`
+}
+
+// currency returns the symbol and the decimals of the native currency of a network, ether unless set by its
+// profile, as for a network given by --rpc-url.
+func (n *network) currency() (string, uint8) {
+	symbol, decimals := "ether", uint8(18)
+	if n != nil && n.Currency != "" {
+		symbol = n.Currency
+	}
+	if n != nil && n.Decimals != 0 {
+		decimals = n.Decimals
+	}
+	return symbol, decimals
+}
+
+// formatAmount formats an amount of wei in the native currency of a network, e.g. 0.5 ETH.
+func (n *network) formatAmount(wei *big.Int) string {
+	symbol, decimals := n.currency()
+	return weiToUnits(wei, decimals).Text('f', -1) + " " + symbol
+}
+
+// config is the content of the ethkit config file.
+type config struct {
+	Current  string     `yaml:"current,omitempty"`
+	Networks []*network `yaml:"networks"`
+}
+
+// configPath returns the location of the config file, which is
+// $ETHKIT_CONFIG when set or ~/.config/ethkit/config.yaml otherwise.
+func configPath() (string, error) {
+	if p := os.Getenv(envConfig); p != "" {
+		return p, nil
+	}
+
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(dir, "ethkit", "config.yaml"), nil
+}
+
+// loadConfig reads the config file. A missing file results in an empty config.
+func loadConfig() (*config, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &config{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("error: unable to parse config file %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// save writes the config file, creating its parent directory when needed.
+func (c *config) save() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+// network returns the network profile with the given name, if any.
+func (c *config) network(name string) *network {
+	for _, n := range c.Networks {
+		if strings.EqualFold(n.Name, name) {
+			return n
+		}
+	}
+	return nil
+}
+
+// addNetwork adds a new network profile, or replaces the one with the same name.
+func (c *config) addNetwork(n *network) {
+	for i, existing := range c.Networks {
+		if strings.EqualFold(existing.Name, n.Name) {
+			c.Networks[i] = n
+			return
+		}
+	}
+	c.Networks = append(c.Networks, n)
+}
+
+// removeNetwork deletes a network profile and reports whether it existed.
+func (c *config) removeNetwork(name string) bool {
+	for i, n := range c.Networks {
+		if strings.EqualFold(n.Name, name) {
+			c.Networks = append(c.Networks[:i], c.Networks[i+1:]...)
+			if strings.EqualFold(c.Current, name) {
+				c.Current = ""
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+)
+
+const (
+	flagDecodeAbi    = "abi"
+	flagDecodeTopics = "topics"
+	flagDecodeData   = "data"
+	flagDecodeJson   = "json"
+)
+
+func init() {
+	rootCmd.AddCommand(NewDecodeCmd())
+}
+
+type decode struct {
+}
+
+// NewDecodeCmd returns a new command to decode calldata and event logs with a contract abi.
+func NewDecodeCmd() *cobra.Command {
+	c := &decode{}
+	cmd := &cobra.Command{
+		Use:   "decode",
+		Short: "Decode transaction calldata and event logs with a contract abi",
+	}
+
+	calldataCmd := &cobra.Command{
+		Use:   "calldata [hex]",
+		Short: "Decode the method and arguments of a transaction calldata",
+		Args:  cobra.ExactArgs(1),
+		RunE:  c.Calldata,
+	}
+	calldataCmd.Flags().String(flagDecodeAbi, "", "path to a contract artifacts or abi json file (required)")
+	addABIFlags(calldataCmd.Flags())
+	calldataCmd.Flags().BoolP(flagDecodeJson, "j", false, "Print as JSON, alias of --output json")
+
+	logCmd := &cobra.Command{
+		Use:   "log",
+		Short: "Decode the event and arguments of a log",
+		Args:  cobra.NoArgs,
+		RunE:  c.Log,
+	}
+	logCmd.Flags().String(flagDecodeAbi, "", "path to a contract artifacts or abi json file (required)")
+	addABIFlags(logCmd.Flags())
+	logCmd.Flags().String(flagDecodeTopics, "", "comma-separated list of the log topics (required)")
+	logCmd.Flags().String(flagDecodeData, "0x", "the log data")
+	logCmd.Flags().BoolP(flagDecodeJson, "j", false, "Print as JSON, alias of --output json")
+
+	cmd.AddCommand(calldataCmd, logCmd)
+
+	return cmd
+}
+
+func (c *decode) Calldata(cmd *cobra.Command, args []string) error {
+	abiSrc, err := abiSourceFor(cmd, flagDecodeAbi)
+	if err != nil {
+		return err
+	}
+
+	if abiSrc == nil {
+		return errors.New("error: please pass --abi")
+	}
+	data, err := hexutil.Decode(args[0])
+	if err != nil {
+		return errors.New("error: please provide a valid 0x-prefixed hex calldata")
+	}
+
+	contractABI, err := loadABI(abiSrc)
+	if err != nil {
+		return err
+	}
+
+	call, err := decodeCalldata(contractABI, data)
+	if err != nil {
+		return err
+	}
+
+	return printResult(cmd, call)
+}
+
+func (c *decode) Log(cmd *cobra.Command, args []string) error {
+	abiSrc, err := abiSourceFor(cmd, flagDecodeAbi)
+	if err != nil {
+		return err
+	}
+	fTopics, err := cmd.Flags().GetString(flagDecodeTopics)
+	if err != nil {
+		return err
+	}
+	fData, err := cmd.Flags().GetString(flagDecodeData)
+	if err != nil {
+		return err
+	}
+
+	if abiSrc == nil {
+		return errors.New("error: please pass --abi")
+	}
+	topics, err := parseTopics(fTopics)
+	if err != nil {
+		return err
+	}
+	data, err := hexutil.Decode(fData)
+	if err != nil {
+		return errors.New("error: please provide a valid 0x-prefixed hex log data")
+	}
+
+	contractABI, err := loadABI(abiSrc)
+	if err != nil {
+		return err
+	}
+
+	event, err := decodeLog(contractABI, topics, data)
+	if err != nil {
+		return err
+	}
+
+	return printResult(cmd, event)
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/0xsequence/ethkit/go-ethereum/accounts/abi"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/0xsequence/ethkit/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+const erc20ABI = `[
+	{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"account","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"submit","stateMutability":"nonpayable","inputs":[{"name":"order","type":"tuple","components":[{"name":"id","type":"bytes32"},{"name":"data","type":"bytes"}]}],"outputs":[]},
+	{"type":"event","name":"Transfer","anonymous":false,"inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]},
+	{"type":"event","name":"Memo","anonymous":false,"inputs":[{"name":"tag","type":"string","indexed":true},{"name":"text","type":"string","indexed":false}]}
+]`
+
+func execDecodeCmd(args string) (string, error) {
+	cmd := NewDecodeCmd()
+	actual := new(bytes.Buffer)
+	cmd.SetOut(actual)
+	cmd.SetErr(actual)
+	cmd.SetArgs(strings.Split(args, " "))
+	if err := cmd.Execute(); err != nil {
+		return "", err
+	}
+
+	return actual.String(), nil
+}
+
+func writeABIFile(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "erc20.json")
+	assert.Nil(t, os.WriteFile(path, []byte(erc20ABI), 0600))
+	return path
+}
+
+func writeArtifactFile(t *testing.T) string {
+	artifact := map[string]any{
+		"contractName": "ERC20",
+		"abi":          json.RawMessage(erc20ABI),
+		"bytecode":     "0x",
+	}
+	data, _ := json.Marshal(artifact)
+	path := filepath.Join(t.TempDir(), "ERC20.json")
+	assert.Nil(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+func Test_LoadABI(t *testing.T) {
+	for _, path := range []string{writeABIFile(t), writeArtifactFile(t)} {
+		contractABI, err := loadABI(&abiSource{path: path})
+		assert.Nil(t, err)
+		assert.Contains(t, contractABI.Methods, "transfer")
+		assert.Contains(t, contractABI.Events, "Transfer")
+	}
+}
+
+func Test_DecodeCalldata(t *testing.T) {
+	contractABI, _ := abi.JSON(strings.NewReader(erc20ABI))
+	to := common.HexToAddress("0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742")
+	data, err := contractABI.Pack("transfer", to, big.NewInt(1000))
+	assert.Nil(t, err)
+
+	call, err := decodeCalldata(contractABI, data)
+	assert.Nil(t, err)
+	assert.Equal(t, "transfer", call.Method)
+	assert.Equal(t, "transfer(address,uint256)", call.Signature)
+	assert.Equal(t, hexutil.Bytes(data[:4]), call.Selector)
+	assert.Equal(t, &DecodedArg{Name: "to", Type: "address", Value: to.Hex()}, call.Args[0])
+	assert.Equal(t, &DecodedArg{Name: "amount", Type: "uint256", Value: "1000"}, call.Args[1])
+
+	_, err = decodeCalldata(contractABI, []byte{1, 2, 3, 4})
+	assert.Equal(t, ErrUnknownSelector, err)
+}
+
+func Test_DecodeCalldata_Tuple(t *testing.T) {
+	contractABI, _ := abi.JSON(strings.NewReader(erc20ABI))
+	order := struct {
+		Id   [32]byte
+		Data []byte
+	}{Id: [32]byte{1}, Data: []byte{0xca, 0xfe}}
+	data, err := contractABI.Pack("submit", order)
+	assert.Nil(t, err)
+
+	call, err := decodeCalldata(contractABI, data)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{
+		"id":   "0x0100000000000000000000000000000000000000000000000000000000000000",
+		"data": "0xcafe",
+	}, call.Args[0].Value)
+}
+
+func Test_DecodeLog(t *testing.T) {
+	contractABI, _ := abi.JSON(strings.NewReader(erc20ABI))
+	from := common.HexToAddress("0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742")
+	to := common.HexToAddress("0x4e59b44847b379578588920cA78FbF26c0B4956C")
+	data, _ := contractABI.Events["Transfer"].Inputs.NonIndexed().Pack(big.NewInt(42))
+	topics := []common.Hash{
+		contractABI.Events["Transfer"].ID,
+		common.BytesToHash(from.Bytes()),
+		common.BytesToHash(to.Bytes()),
+	}
+
+	event, err := decodeLog(contractABI, topics, data)
+	assert.Nil(t, err)
+	assert.Equal(t, "Transfer", event.Event)
+	assert.Equal(t, &DecodedArg{Name: "from", Type: "address", Indexed: true, Value: from.Hex()}, event.Args[0])
+	assert.Equal(t, &DecodedArg{Name: "to", Type: "address", Indexed: true, Value: to.Hex()}, event.Args[1])
+	assert.Equal(t, &DecodedArg{Name: "value", Type: "uint256", Value: "42"}, event.Args[2])
+
+	_, err = decodeLog(contractABI, topics[:2], data)
+	assert.NotNil(t, err)
+}
+
+func Test_DecodeLog_HashedTopic(t *testing.T) {
+	contractABI, _ := abi.JSON(strings.NewReader(erc20ABI))
+	data, _ := contractABI.Events["Memo"].Inputs.NonIndexed().Pack("hello")
+	tagHash := crypto.Keccak256Hash([]byte("greeting"))
+
+	event, err := decodeLog(contractABI, []common.Hash{contractABI.Events["Memo"].ID, tagHash}, data)
+	assert.Nil(t, err)
+	assert.Equal(t, tagHash.Hex(), event.Args[0].Value)
+	assert.Equal(t, "hello", event.Args[1].Value)
+}
+
+func Test_DecodeCmd_Calldata(t *testing.T) {
+	path := writeArtifactFile(t)
+	contractABI, _ := abi.JSON(strings.NewReader(erc20ABI))
+	data, _ := contractABI.Pack("balanceOf", common.HexToAddress("0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742"))
+
+	res, err := execDecodeCmd("calldata --abi " + path + " --json " + hexutil.Encode(data))
+	assert.Nil(t, err)
+	var call DecodedCall
+	assert.Nil(t, json.Unmarshal([]byte(res), &call))
+	assert.Equal(t, "balanceOf(address)", call.Signature)
+}
+
+func Test_DecodeCmd_CalldataContract(t *testing.T) {
+	path := writeJSONFile(t, "combined.json", map[string]any{
+		"contracts": map[string]any{
+			"contracts/Vault.sol:Vault": map[string]any{"abi": json.RawMessage(testLinkedABI), "bin": "", "bin-runtime": ""},
+			"contracts/ERC20.sol:ERC20": map[string]any{"abi": json.RawMessage(erc20ABI), "bin": "", "bin-runtime": ""},
+		},
+		"version": "0.8.20",
+	})
+	contractABI, _ := abi.JSON(strings.NewReader(erc20ABI))
+	data, _ := contractABI.Pack("balanceOf", common.HexToAddress("0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742"))
+
+	res, err := execDecodeCmd("calldata --abi " + path + " --contract ERC20 --format solc-combined " + hexutil.Encode(data))
+	assert.Nil(t, err)
+	assert.Contains(t, res, "balanceOf(address)")
+
+	_, err = execDecodeCmd("calldata --abi " + path + " " + hexutil.Encode(data))
+	assert.ErrorContains(t, err, "please pass --contract with one of: contracts/ERC20.sol:ERC20, contracts/Vault.sol:Vault")
+
+	_, err = execDecodeCmd("calldata --abi " + writeABIFile(t) + " --contract ERC20 " + hexutil.Encode(data))
+	assert.ErrorContains(t, err, "is an abi json file")
+}
+
+func Test_DecodeCmd_Log(t *testing.T) {
+	path := writeABIFile(t)
+	contractABI, _ := abi.JSON(strings.NewReader(erc20ABI))
+	from := common.HexToAddress("0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742")
+	data, _ := contractABI.Events["Transfer"].Inputs.NonIndexed().Pack(big.NewInt(42))
+	topics := []string{
+		contractABI.Events["Transfer"].ID.Hex(),
+		common.BytesToHash(from.Bytes()).Hex(),
+		common.BytesToHash(from.Bytes()).Hex(),
+	}
+
+	res, err := execDecodeCmd("log --abi " + path + " --topics " + strings.Join(topics, ",") + " --data " + hexutil.Encode(data))
+	assert.Nil(t, err)
+	assert.Contains(t, res, "Transfer(address,address,uint256)")
+
+	_, err = execDecodeCmd("log --abi " + path + " --topics 0x1234")
+	assert.NotNil(t, err)
+}
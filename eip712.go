@@ -0,0 +1,369 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/0xsequence/ethkit/go-ethereum/common/math"
+	"github.com/0xsequence/ethkit/go-ethereum/crypto"
+)
+
+// EIP-712 typed structured data hashing, https://eips.ethereum.org/EIPS/eip-712
+
+const eip712Domain = "EIP712Domain"
+
+// eip712DomainFields are the fields of the EIP712Domain type, in their canonical order.
+var eip712DomainFields = []typedDataField{
+	{Name: "name", Type: "string"},
+	{Name: "version", Type: "string"},
+	{Name: "chainId", Type: "uint256"},
+	{Name: "verifyingContract", Type: "address"},
+	{Name: "salt", Type: "bytes32"},
+}
+
+var (
+	typedDataIntType   = regexp.MustCompile(`^(u?)int(\d*)$`)
+	typedDataBytesType = regexp.MustCompile(`^bytes(\d+)$`)
+	typedDataArrayType = regexp.MustCompile(`^(.+)\[(\d*)\]$`)
+)
+
+// typedData is the eth_signTypedData_v4 JSON of EIP-712 typed structured data.
+type typedData struct {
+	Types       map[string][]typedDataField `json:"types"`
+	PrimaryType string                      `json:"primaryType"`
+	Domain      map[string]any              `json:"domain"`
+	Message     map[string]any              `json:"message"`
+}
+
+type typedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// readTypedData reads the typed data JSON of a file, or of stdin when the path is "-".
+func readTypedData(path string) (*typedData, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(stdinReader)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTypedData(data)
+}
+
+// parseTypedData parses the typed data JSON, keeping the numbers as json.Number to not lose precision.
+func parseTypedData(data []byte) (*typedData, error) {
+	td := &typedData{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(td); err != nil {
+		return nil, fmt.Errorf("error: invalid typed data JSON: %w", err)
+	}
+
+	if td.PrimaryType == "" {
+		return nil, errors.New("error: invalid typed data, missing primaryType")
+	}
+	if td.Types == nil {
+		td.Types = map[string][]typedDataField{}
+	}
+	if td.Domain == nil {
+		td.Domain = map[string]any{}
+	}
+
+	// the domain type is often left out of the types, in which case it is built from the domain fields
+	if _, ok := td.Types[eip712Domain]; !ok {
+		var fields []typedDataField
+		for _, f := range eip712DomainFields {
+			if _, ok := td.Domain[f.Name]; ok {
+				fields = append(fields, f)
+			}
+		}
+		td.Types[eip712Domain] = fields
+	}
+	if _, ok := td.Types[td.PrimaryType]; !ok {
+		return nil, fmt.Errorf("error: invalid typed data, primaryType %s is not defined in types", td.PrimaryType)
+	}
+
+	return td, nil
+}
+
+// domainSeparator returns the hash of the domain.
+func (td *typedData) domainSeparator() ([]byte, error) {
+	return td.hashStruct(eip712Domain, td.Domain)
+}
+
+// structHash returns the hash of the message, which is empty when the primary type is the domain itself.
+func (td *typedData) structHash() ([]byte, error) {
+	if td.PrimaryType == eip712Domain {
+		return nil, nil
+	}
+	return td.hashStruct(td.PrimaryType, td.Message)
+}
+
+// digest returns the hash to sign, keccak256("\x19\x01" ‖ domainSeparator ‖ hashStruct(message)).
+func (td *typedData) digest() (domainSeparator, structHash, digest []byte, err error) {
+	if domainSeparator, err = td.domainSeparator(); err != nil {
+		return nil, nil, nil, fmt.Errorf("error: invalid typed data domain: %w", err)
+	}
+	if structHash, err = td.structHash(); err != nil {
+		return nil, nil, nil, fmt.Errorf("error: invalid typed data message: %w", err)
+	}
+
+	digest = crypto.Keccak256([]byte{0x19, 0x01}, domainSeparator, structHash)
+	return domainSeparator, structHash, digest, nil
+}
+
+func (td *typedData) hashStruct(typeName string, data map[string]any) ([]byte, error) {
+	encoded, err := td.encodeData(typeName, data)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(encoded), nil
+}
+
+// encodeType returns the encoding of a type, e.g. Mail(Person from,Person to,string contents)Person(string name,address wallet),
+// followed by the types it references sorted by name.
+func (td *typedData) encodeType(typeName string) (string, error) {
+	deps := map[string]bool{}
+	if err := td.dependencies(typeName, deps); err != nil {
+		return "", err
+	}
+	delete(deps, typeName)
+
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range append([]string{typeName}, names...) {
+		fields := make([]string, len(td.Types[name]))
+		for i, f := range td.Types[name] {
+			fields[i] = f.Type + " " + f.Name
+		}
+		b.WriteString(name + "(" + strings.Join(fields, ",") + ")")
+	}
+	return b.String(), nil
+}
+
+// dependencies collects a struct type and the struct types it references.
+func (td *typedData) dependencies(typeName string, deps map[string]bool) error {
+	if deps[typeName] {
+		return nil
+	}
+	fields, ok := td.Types[typeName]
+	if !ok {
+		return fmt.Errorf("type %s is not defined", typeName)
+	}
+	deps[typeName] = true
+
+	for _, f := range fields {
+		base := f.Type
+		for typedDataArrayType.MatchString(base) {
+			base = typedDataArrayType.FindStringSubmatch(base)[1]
+		}
+		if _, ok := td.Types[base]; ok {
+			if err := td.dependencies(base, deps); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (td *typedData) encodeData(typeName string, data map[string]any) ([]byte, error) {
+	encodedType, err := td.encodeType(typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := td.Types[typeName]
+	for name := range data {
+		found := false
+		for _, f := range fields {
+			found = found || f.Name == name
+		}
+		if !found {
+			return nil, fmt.Errorf("%s has no field %s", typeName, name)
+		}
+	}
+
+	encoded := crypto.Keccak256([]byte(encodedType))
+	for _, f := range fields {
+		value, ok := data[f.Name]
+		if !ok {
+			return nil, fmt.Errorf("missing value of %s.%s", typeName, f.Name)
+		}
+		word, err := td.encodeValue(f.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of %s.%s: %w", typeName, f.Name, err)
+		}
+		encoded = append(encoded, word...)
+	}
+	return encoded, nil
+}
+
+// encodeValue encodes a value into a 32 bytes word, dynamic values, arrays and structs being hashed.
+func (td *typedData) encodeValue(typeName string, value any) ([]byte, error) {
+	if m := typedDataArrayType.FindStringSubmatch(typeName); m != nil {
+		elems, ok := value.([]any)
+		if !ok {
+			return nil, fmt.Errorf("expected an array for %s", typeName)
+		}
+		if m[2] != "" {
+			if n, _ := strconv.Atoi(m[2]); n != len(elems) {
+				return nil, fmt.Errorf("expected %d elements for %s, got %d", n, typeName, len(elems))
+			}
+		}
+		var encoded []byte
+		for _, elem := range elems {
+			word, err := td.encodeValue(m[1], elem)
+			if err != nil {
+				return nil, err
+			}
+			encoded = append(encoded, word...)
+		}
+		return crypto.Keccak256(encoded), nil
+	}
+
+	if _, ok := td.Types[typeName]; ok {
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected an object for %s", typeName)
+		}
+		return td.hashStruct(typeName, obj)
+	}
+
+	switch typeName {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return nil, errors.New("expected a string")
+		}
+		return crypto.Keccak256([]byte(s)), nil
+
+	case "bytes":
+		b, err := typedDataBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.Keccak256(b), nil
+
+	case "bool":
+		var b bool
+		switch t := value.(type) {
+		case bool:
+			b = t
+		case string:
+			var err error
+			if b, err = strconv.ParseBool(t); err != nil {
+				return nil, errors.New("expected a bool")
+			}
+		default:
+			return nil, errors.New("expected a bool")
+		}
+		word := make([]byte, 32)
+		if b {
+			word[31] = 1
+		}
+		return word, nil
+
+	case "address":
+		s, ok := value.(string)
+		if !ok || !common.IsHexAddress(s) {
+			return nil, errors.New("expected an address")
+		}
+		return common.LeftPadBytes(common.HexToAddress(s).Bytes(), 32), nil
+	}
+
+	if m := typedDataBytesType.FindStringSubmatch(typeName); m != nil {
+		size, _ := strconv.Atoi(m[1])
+		if size < 1 || size > 32 {
+			return nil, fmt.Errorf("unsupported type %s", typeName)
+		}
+		b, err := typedDataBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != size {
+			return nil, fmt.Errorf("expected %d bytes, got %d", size, len(b))
+		}
+		return common.RightPadBytes(b, 32), nil
+	}
+
+	if m := typedDataIntType.FindStringSubmatch(typeName); m != nil {
+		bits := 256
+		if m[2] != "" {
+			bits, _ = strconv.Atoi(m[2])
+		}
+		if bits < 8 || bits > 256 || bits%8 != 0 {
+			return nil, fmt.Errorf("unsupported type %s", typeName)
+		}
+		n, err := typedDataInt(value)
+		if err != nil {
+			return nil, err
+		}
+
+		lower, upper := new(big.Int), new(big.Int).Lsh(big.NewInt(1), uint(bits))
+		if m[1] == "" {
+			upper.Rsh(upper, 1)
+			lower.Neg(upper)
+		}
+		if n.Cmp(lower) < 0 || n.Cmp(upper) >= 0 {
+			return nil, fmt.Errorf("%s overflows %s", n, typeName)
+		}
+		// negative integers are encoded in two's complement
+		return math.U256Bytes(new(big.Int).Set(n)), nil
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", typeName)
+}
+
+// typedDataBytes decodes a 0x-prefixed hex value.
+func typedDataBytes(value any) ([]byte, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, errors.New("expected 0x-prefixed hex bytes")
+	}
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("expected 0x-prefixed hex bytes: %w", err)
+	}
+	return b, nil
+}
+
+// typedDataInt parses an integer given as a JSON number, or as a decimal or 0x-prefixed hex string.
+func typedDataInt(value any) (*big.Int, error) {
+	var s string
+	switch t := value.(type) {
+	case json.Number:
+		s = t.String()
+	case string:
+		s = t
+	default:
+		return nil, errors.New("expected an integer")
+	}
+
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, fmt.Errorf("expected an integer, got %q", s)
+	}
+	return n, nil
+}
@@ -4,6 +4,7 @@ import "errors"
 
 var (
 	ErrInvalidBlockInfo = errors.New("invalid block height, tag or hash")
-	ErrInvalidRpcUrl = errors.New("invalid rpc url")
-	ErrBlockNotFound = errors.New("block not found")
-)
\ No newline at end of file
+	ErrInvalidRpcUrl    = errors.New("error: please provide a valid rpc url (e.g. https://nodes.sequence.app/mainnet)")
+	ErrNoRpcUrl         = errors.New("error: no rpc url, please pass --rpc-url or --network, set ETHKIT_RPC_URL or select a default network with `ethkit network use`")
+	ErrBlockNotFound    = errors.New("block not found")
+)
@@ -0,0 +1,286 @@
+// Package rpctest provides an in-process JSON-RPC server for the command tests. The server replays the
+// interactions of a fixture file and, when the ETHKIT_RPC_RECORD environment variable is set to the url of
+// a real node, forwards the requests to the node and records the session into the fixture file instead.
+//
+// To record or refresh a fixture:
+//
+//	ETHKIT_RPC_RECORD=https://nodes.sequence.app/mainnet go test -run Test_BlockCmd ./...
+package rpctest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// EnvRecord is the environment variable holding the url of the node to record fixtures from.
+const EnvRecord = "ETHKIT_RPC_RECORD"
+
+// Interaction is a recorded JSON-RPC call with either its result or its error.
+type Interaction struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC error.
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Fixture is the content of a fixture file.
+type Fixture struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Server is a fake JSON-RPC node serving the interactions of a fixture.
+type Server struct {
+	// URL is the url of the server, to be passed to the commands with --rpc-url.
+	URL string
+
+	t        testing.TB
+	path     string
+	upstream string
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         map[*Interaction]bool
+}
+
+// NewServer starts a server replaying the fixture file at path, or recording it when ETHKIT_RPC_RECORD is set.
+// The server is closed, and a recorded fixture is written, when the test ends. An empty path starts a server
+// with no interactions, to be registered with Handle.
+func NewServer(t testing.TB, path string) *Server {
+	t.Helper()
+
+	s := &Server{
+		t:    t,
+		path: path,
+		used: make(map[*Interaction]bool),
+	}
+
+	if path != "" {
+		s.upstream = os.Getenv(EnvRecord)
+		if s.upstream == "" {
+			fixture, err := ReadFixture(path)
+			if err != nil {
+				t.Fatalf("rpctest: %v", err)
+			}
+			s.interactions = fixture.Interactions
+		}
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = srv.URL
+
+	t.Cleanup(func() {
+		srv.Close()
+		if s.upstream != "" {
+			if err := WriteFixture(s.path, &Fixture{Interactions: s.interactions}); err != nil {
+				t.Errorf("rpctest: %v", err)
+			}
+		}
+	})
+
+	return s
+}
+
+// Handle registers the result of a method called with the given params. A nil params matches any params.
+func (s *Server) Handle(method string, params any, result any) {
+	s.add(&Interaction{Method: method, Params: mustMarshal(s.t, params), Result: mustMarshal(s.t, result)})
+}
+
+// HandleError registers the error of a method called with the given params. A nil params matches any params.
+func (s *Server) HandleError(method string, params any, code int, message string) {
+	s.add(&Interaction{Method: method, Params: mustMarshal(s.t, params), Error: &Error{Code: code, Message: message}})
+}
+
+func (s *Server) add(i *Interaction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.interactions = append(s.interactions, i)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if s.upstream != "" {
+		s.record(w, body)
+		return
+	}
+
+	batch := len(bytes.TrimSpace(body)) > 0 && bytes.TrimSpace(body)[0] == '['
+
+	var reqs []*request
+	if batch {
+		err = json.Unmarshal(body, &reqs)
+	} else {
+		req := &request{}
+		err = json.Unmarshal(body, req)
+		reqs = append(reqs, req)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resps := make([]*response, len(reqs))
+	for i, req := range reqs {
+		resps[i] = s.reply(req)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if batch {
+		json.NewEncoder(w).Encode(resps)
+	} else {
+		json.NewEncoder(w).Encode(resps[0])
+	}
+}
+
+// reply answers a request with the first unused matching interaction, reusing the last matching one
+// when they have all been used already.
+func (s *Server) reply(req *request) *response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var match *Interaction
+	for _, i := range s.interactions {
+		if i.Method != req.Method || !sameParams(i.Params, req.Params) {
+			continue
+		}
+		match = i
+		if !s.used[i] {
+			break
+		}
+	}
+
+	if match == nil {
+		s.t.Errorf("rpctest: no recorded response for %s %s", req.Method, req.Params)
+		return &response{JSONRPC: "2.0", ID: req.ID, Error: &Error{
+			Code:    -32601,
+			Message: fmt.Sprintf("rpctest: no recorded response for %s %s", req.Method, req.Params),
+		}}
+	}
+	s.used[match] = true
+
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: match.Result, Error: match.Error}
+}
+
+// record forwards a request to the upstream node and records its response.
+func (s *Server) record(w http.ResponseWriter, body []byte) {
+	res, err := http.Post(s.upstream, "application/json", bytes.NewReader(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	var reqs []*request
+	var resps []*response
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+		json.Unmarshal(body, &reqs)
+		json.Unmarshal(data, &resps)
+	} else {
+		req, resp := &request{}, &response{}
+		json.Unmarshal(body, req)
+		json.Unmarshal(data, resp)
+		reqs, resps = []*request{req}, []*response{resp}
+	}
+
+	s.mu.Lock()
+	for _, req := range reqs {
+		for _, resp := range resps {
+			if bytes.Equal(resp.ID, req.ID) {
+				s.interactions = append(s.interactions, &Interaction{Method: req.Method, Params: req.Params, Result: resp.Result, Error: resp.Error})
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(res.StatusCode)
+	w.Write(data)
+}
+
+// ReadFixture reads a fixture file.
+func ReadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fixture := &Fixture{}
+	if err := json.Unmarshal(data, fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+	return fixture, nil
+}
+
+// WriteFixture writes a fixture file, creating its directory if needed.
+func WriteFixture(path string, fixture *Fixture) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// sameParams compares two JSON params regardless of their formatting. Null or missing expected params match any params.
+func sameParams(expected, actual json.RawMessage) bool {
+	if len(expected) == 0 || string(expected) == "null" {
+		return true
+	}
+
+	var e, a bytes.Buffer
+	if json.Compact(&e, expected) != nil || json.Compact(&a, actual) != nil {
+		return false
+	}
+	return bytes.Equal(e.Bytes(), a.Bytes())
+}
+
+func mustMarshal(t testing.TB, v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("rpctest: %v", err)
+	}
+	return data
+}
@@ -0,0 +1,78 @@
+package rpctest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func post(t *testing.T, url, body string) string {
+	res, err := http.Post(url, "application/json", strings.NewReader(body))
+	assert.Nil(t, err)
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	assert.Nil(t, err)
+	return strings.TrimSpace(string(data))
+}
+
+func Test_Server_Replay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	assert.Nil(t, WriteFixture(path, &Fixture{Interactions: []*Interaction{
+		{Method: "eth_blockNumber", Params: json.RawMessage(`[]`), Result: json.RawMessage(`"0x1"`)},
+		{Method: "eth_blockNumber", Params: json.RawMessage(`[]`), Result: json.RawMessage(`"0x2"`)},
+		{Method: "eth_getBalance", Params: json.RawMessage(`["0xabc", "latest"]`), Error: &Error{Code: -32000, Message: "header not found"}},
+	}}))
+
+	srv := NewServer(t, path)
+
+	// interactions are replayed in order, the last one being reused
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":"0x1"}`, post(t, srv.URL, `{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`))
+	assert.Equal(t, `{"jsonrpc":"2.0","id":2,"result":"0x2"}`, post(t, srv.URL, `{"jsonrpc":"2.0","id":2,"method":"eth_blockNumber","params":[]}`))
+	assert.Equal(t, `{"jsonrpc":"2.0","id":3,"result":"0x2"}`, post(t, srv.URL, `{"jsonrpc":"2.0","id":3,"method":"eth_blockNumber","params":[]}`))
+
+	assert.Equal(t,
+		`[{"jsonrpc":"2.0","id":4,"error":{"code":-32000,"message":"header not found"}}]`,
+		post(t, srv.URL, `[{"jsonrpc":"2.0","id":4,"method":"eth_getBalance","params":["0xabc","latest"]}]`))
+}
+
+func Test_Server_Handle(t *testing.T) {
+	srv := NewServer(t, "")
+	srv.Handle("eth_chainId", nil, "0x1")
+	srv.HandleError("eth_call", []any{"0xabc"}, 3, "execution reverted")
+
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":"0x1"}`, post(t, srv.URL, `{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":["any"]}`))
+	assert.Equal(t, `{"jsonrpc":"2.0","id":2,"error":{"code":3,"message":"execution reverted"}}`, post(t, srv.URL, `{"jsonrpc":"2.0","id":2,"method":"eth_call","params":["0xabc"]}`))
+}
+
+func Test_Server_Record(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req request
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(&response{JSONRPC: "2.0", ID: req.ID, Result: json.RawMessage(`"0x2a"`)})
+	}))
+	defer upstream.Close()
+
+	path := filepath.Join(t.TempDir(), "rpc", "recorded.json")
+
+	t.Run("record", func(t *testing.T) {
+		t.Setenv(EnvRecord, upstream.URL)
+		srv := NewServer(t, path)
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"result":"0x2a"}`, post(t, srv.URL, `{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`))
+	})
+
+	fixture, err := ReadFixture(path)
+	assert.Nil(t, err)
+	assert.Len(t, fixture.Interactions, 1)
+	assert.Equal(t, "eth_blockNumber", fixture.Interactions[0].Method)
+	assert.True(t, bytes.Equal(json.RawMessage(`"0x2a"`), fixture.Interactions[0].Result))
+
+	srv := NewServer(t, path)
+	assert.Equal(t, `{"jsonrpc":"2.0","id":7,"result":"0x2a"}`, post(t, srv.URL, `{"jsonrpc":"2.0","id":7,"method":"eth_blockNumber","params":[]}`))
+}
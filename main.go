@@ -34,6 +34,9 @@ func init() {
 	}
 
 	rootCmd.AddCommand(versionCmd)
+
+	rootCmd.PersistentFlags().StringP(flagNetwork, "n", "", "The name of a network defined in the config file (see ethkit network)")
+	rootCmd.PersistentFlags().StringP(flagOutput, "o", outputTable, "The output format: table, json, jsonl, yaml, csv or template=<tmpl>")
 }
 
 func main() {
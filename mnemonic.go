@@ -0,0 +1,270 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const flagMnemonicWords = "words"
+
+// defaultMnemonicWords is the number of words of the mnemonics of new wallets.
+const defaultMnemonicWords = 24
+
+// mnemonicWordCounts are the BIP-39 mnemonic lengths, of 128 to 256 bits of entropy.
+var mnemonicWordCounts = []int{12, 15, 18, 21, 24}
+
+func init() {
+	rootCmd.AddCommand(NewMnemonicCmd())
+}
+
+type mnemonicCmd struct {
+}
+
+// NewMnemonicCmd returns a new command to generate, validate and inspect BIP-39 mnemonics.
+func NewMnemonicCmd() *cobra.Command {
+	c := &mnemonicCmd{}
+	cmd := &cobra.Command{
+		Use:   "mnemonic",
+		Short: "Generate, validate and inspect BIP-39 mnemonics",
+	}
+
+	generateCmd := &cobra.Command{
+		Use:     "generate",
+		Short:   "Print a new random mnemonic (danger!)",
+		Example: `  ethkit mnemonic generate --words 12`,
+		Args:    cobra.NoArgs,
+		RunE:    c.Generate,
+	}
+	generateCmd.Flags().Int(flagMnemonicWords, defaultMnemonicWords, "The number of words of the mnemonic: 12, 15, 18, 21 or 24")
+
+	validateCmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check the words and the checksum of a mnemonic read from stdin",
+		Example: `  ethkit mnemonic validate
+  ethkit mnemonic validate < mnemonic.txt`,
+		Args: cobra.NoArgs,
+		RunE: c.Validate,
+	}
+
+	infoCmd := &cobra.Command{
+		Use:     "info",
+		Short:   "Print the entropy and checksum of a mnemonic read from stdin (danger!)",
+		Example: `  ethkit mnemonic info < mnemonic.txt`,
+		Args:    cobra.NoArgs,
+		RunE:    c.Info,
+	}
+
+	cmd.AddCommand(generateCmd, validateCmd, infoCmd)
+
+	return cmd
+}
+
+func (c *mnemonicCmd) Generate(cmd *cobra.Command, args []string) error {
+	fWords, err := cmd.Flags().GetInt(flagMnemonicWords)
+	if err != nil {
+		return err
+	}
+
+	bits, err := mnemonicEntropyBits(fWords)
+	if err != nil {
+		return err
+	}
+	entropy, err := bip39.NewEntropy(bits)
+	if err != nil {
+		return err
+	}
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return err
+	}
+
+	return printResult(cmd, &WalletMnemonic{Mnemonic: mnemonic})
+}
+
+func (c *mnemonicCmd) Validate(cmd *cobra.Command, args []string) error {
+	input, err := readMaskedInput("Enter the mnemonic to validate: ")
+	if err != nil {
+		return err
+	}
+	defer zeroBytes(input)
+	mnemonic := normalizeMnemonic(string(input))
+
+	problems := mnemonicProblems(mnemonic)
+	res := &MnemonicValidation{Valid: len(problems) == 0, Words: len(strings.Fields(mnemonic)), Problems: problems}
+	if err := printResult(cmd, res); err != nil {
+		return err
+	}
+
+	if !res.Valid {
+		return errors.New("error: invalid mnemonic")
+	}
+	return nil
+}
+
+func (c *mnemonicCmd) Info(cmd *cobra.Command, args []string) error {
+	mnemonic, err := readMnemonic("Enter the mnemonic to inspect: ")
+	if err != nil {
+		return err
+	}
+
+	words := strings.Fields(mnemonic)
+	entropy, err := bip39.EntropyFromMnemonic(mnemonic)
+	if err != nil {
+		return err
+	}
+
+	// the checksum is held by the last bits of the last word, one bit per 32 bits of entropy
+	checksumBits := len(entropy) * 8 / 32
+	last, _ := bip39.GetWordIndex(words[len(words)-1])
+
+	return printResult(cmd, &MnemonicInfo{
+		Words:        len(words),
+		EntropyBits:  len(entropy) * 8,
+		Entropy:      fmt.Sprintf("0x%x", entropy),
+		ChecksumBits: checksumBits,
+		Checksum:     fmt.Sprintf("%0*b", checksumBits, last&(1<<checksumBits-1)),
+	})
+}
+
+// MnemonicValidation is the result of the validation of a mnemonic.
+type MnemonicValidation struct {
+	Valid    bool     `json:"valid"`
+	Words    int      `json:"words"`
+	Problems []string `json:"problems"`
+}
+
+// String overrides the standard behavior for MnemonicValidation "to-string".
+func (v *MnemonicValidation) String() string {
+	if v.Valid {
+		return fmt.Sprintf("=> valid mnemonic of %d words", v.Words)
+	}
+	s := "=> invalid mnemonic:"
+	for _, p := range v.Problems {
+		s += "\n=> " + p
+	}
+	return s
+}
+
+// MnemonicInfo is the entropy encoded by a mnemonic, followed by its checksum.
+type MnemonicInfo struct {
+	Words        int    `json:"words"`
+	EntropyBits  int    `json:"entropyBits"`
+	Entropy      string `json:"entropy"`
+	ChecksumBits int    `json:"checksumBits"`
+	Checksum     string `json:"checksum"`
+}
+
+// mnemonicEntropyBits returns the bits of entropy of a mnemonic of a number of words, each word encoding 11 bits
+// of the entropy followed by its checksum.
+func mnemonicEntropyBits(words int) (int, error) {
+	for _, n := range mnemonicWordCounts {
+		if n == words {
+			return words / 3 * 32, nil
+		}
+	}
+	return 0, fmt.Errorf("error: --%s must be one of 12, 15, 18, 21 or 24, got %d", flagMnemonicWords, words)
+}
+
+// normalizeMnemonic lowercases a mnemonic and separates its words with single spaces.
+func normalizeMnemonic(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// readMnemonic reads a mnemonic, masked on the terminal, and validates it.
+func readMnemonic(prompt string) (string, error) {
+	input, err := readMaskedInput(prompt)
+	if err != nil {
+		return "", err
+	}
+	defer zeroBytes(input)
+	mnemonic := normalizeMnemonic(string(input))
+	if mnemonic == "" {
+		return "", errors.New("error: mnemonic cannot be empty")
+	}
+	if err := validateMnemonic(mnemonic); err != nil {
+		return "", err
+	}
+	return mnemonic, nil
+}
+
+// validateMnemonic returns the problems of a normalized mnemonic as an error.
+func validateMnemonic(mnemonic string) error {
+	if problems := mnemonicProblems(mnemonic); len(problems) > 0 {
+		return errors.New("error: invalid mnemonic, " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// mnemonicProblems checks the number of words of a normalized mnemonic, that they are all in the BIP-39 english
+// wordlist, suggesting the nearest word of a misspelled one, and its checksum.
+func mnemonicProblems(mnemonic string) []string {
+	words := strings.Fields(mnemonic)
+
+	problems := []string{}
+	if _, err := mnemonicEntropyBits(len(words)); err != nil {
+		problems = append(problems, fmt.Sprintf("a mnemonic has 12, 15, 18, 21 or 24 words, got %d", len(words)))
+	}
+	for i, word := range words {
+		if _, ok := bip39.GetWordIndex(word); !ok {
+			problems = append(problems, fmt.Sprintf("word %d %q is not in the BIP-39 english wordlist, did you mean %q?", i+1, word, suggestMnemonicWord(word)))
+		}
+	}
+	if len(problems) == 0 {
+		if _, err := bip39.EntropyFromMnemonic(mnemonic); err != nil {
+			problems = append(problems, "invalid checksum, a word is wrong or the words are not in order")
+		}
+	}
+	return problems
+}
+
+// suggestMnemonicWord returns the word of the BIP-39 english wordlist nearest to a misspelled word. The words are
+// unique by their first 4 letters, which are preferred over the edit distance.
+func suggestMnemonicWord(word string) string {
+	wordList := bip39.GetWordList()
+	if len(word) >= 4 {
+		for _, w := range wordList {
+			if strings.HasPrefix(w, word[:4]) {
+				return w
+			}
+		}
+	}
+
+	best, bestDistance := "", -1
+	for _, w := range wordList {
+		if d := editDistance(word, w); bestDistance < 0 || d < bestDistance {
+			best, bestDistance = w, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the optimal string alignment distance of two words, the Levenshtein distance where the
+// transposition of two adjacent letters, a common typo, is a single edit.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func execMnemonicCmd(args string, stdin string) (string, error) {
+	defer func(r *bufio.Reader) { stdinReader = r }(stdinReader)
+	stdinReader = bufio.NewReader(strings.NewReader(stdin))
+
+	cmd := NewMnemonicCmd()
+	actual := new(bytes.Buffer)
+	cmd.SetOut(actual)
+	cmd.SetErr(actual)
+	cmd.SetArgs(strings.Split(args, " "))
+	if err := cmd.Execute(); err != nil {
+		return "", err
+	}
+
+	return actual.String(), nil
+}
+
+func Test_MnemonicCmd_Generate(t *testing.T) {
+	for _, words := range mnemonicWordCounts {
+		res, err := execMnemonicCmd(fmt.Sprintf("generate --words %d", words), "")
+		assert.Nil(t, err)
+
+		lines := strings.Split(strings.TrimSpace(res), "\n")
+		mnemonic := strings.TrimPrefix(lines[len(lines)-1], "=> ")
+		assert.Len(t, strings.Fields(mnemonic), words)
+		assert.Nil(t, validateMnemonic(mnemonic))
+	}
+
+	_, err := execMnemonicCmd("generate --words 13", "")
+	assert.NotNil(t, err)
+}
+
+func Test_MnemonicCmd_Validate(t *testing.T) {
+	res, err := execMnemonicCmd("validate", "Test test test test test test test test test test test  junk\n")
+	assert.Nil(t, err)
+	assert.Equal(t, "=> valid mnemonic of 12 words\n", res)
+
+	for _, mnemonic := range []string{
+		"test test test test test test test test test test test",
+		"test test test test test test test test test test test test",
+		"tset test test test test test test test test test test junk",
+	} {
+		_, err := execMnemonicCmd("validate", mnemonic+"\n")
+		assert.NotNil(t, err, mnemonic)
+	}
+
+	assert.Equal(t, []string{`word 1 "tset" is not in the BIP-39 english wordlist, did you mean "test"?`},
+		mnemonicProblems("tset test test test test test test test test test test junk"))
+	assert.Equal(t, []string{"invalid checksum, a word is wrong or the words are not in order"},
+		mnemonicProblems("junk test test test test test test test test test test test"))
+	assert.Equal(t, "abandon", suggestMnemonicWord("abandn"))
+	assert.Equal(t, "zoo", suggestMnemonicWord("zo"))
+}
+
+func Test_MnemonicCmd_Info(t *testing.T) {
+	res, err := execMnemonicCmd("info", "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about\n")
+	assert.Nil(t, err)
+	assert.Equal(t, `words               | 12
+entropyBits         | 128
+entropy             | 0x00000000000000000000000000000000
+checksumBits        | 4
+checksum            | 0011
+
+`, res)
+
+	res, err = execMnemonicCmd("info", "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote\n")
+	assert.Nil(t, err)
+	assert.Contains(t, res, "0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+	assert.Contains(t, res, "entropyBits         | 256")
+}
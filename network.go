@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagNetworkUrl      = "url"
+	flagNetworkChainId  = "chain-id"
+	flagNetworkExplorer = "explorer"
+	flagNetworkCurrency = "currency"
+	flagNetworkDecimals = "decimals"
+)
+
+func init() {
+	rootCmd.AddCommand(NewNetworkCmd())
+}
+
+type networkCmd struct {
+}
+
+// NewNetworkCmd returns a new command to manage the network profiles of the config file.
+func NewNetworkCmd() *cobra.Command {
+	c := &networkCmd{}
+	cmd := &cobra.Command{
+		Use:   "network",
+		Short: "Manage the named networks of the config file",
+	}
+
+	listCmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the configured networks",
+		Aliases: []string{"ls"},
+		Args:    cobra.NoArgs,
+		RunE:    c.List,
+	}
+
+	addCmd := &cobra.Command{
+		Use:   "add [name]",
+		Short: "Add or replace a network",
+		Args:  cobra.ExactArgs(1),
+		RunE:  c.Add,
+	}
+	addCmd.Flags().String(flagNetworkUrl, "", "The RPC endpoint of the network (required)")
+	addCmd.Flags().Uint64(flagNetworkChainId, 0, "The chain ID of the network")
+	addCmd.Flags().String(flagNetworkExplorer, "", "The block explorer URL of the network")
+	addCmd.Flags().String(flagNetworkCurrency, "ETH", "The symbol of the native currency")
+	addCmd.Flags().Uint8(flagNetworkDecimals, 18, "The decimals of the native currency")
+
+	removeCmd := &cobra.Command{
+		Use:     "remove [name]",
+		Short:   "Remove a network",
+		Aliases: []string{"rm"},
+		Args:    cobra.ExactArgs(1),
+		RunE:    c.Remove,
+	}
+
+	useCmd := &cobra.Command{
+		Use:   "use [name]",
+		Short: "Set the default network used when no --rpc-url or --network is provided",
+		Args:  cobra.ExactArgs(1),
+		RunE:  c.Use,
+	}
+
+	cmd.AddCommand(listCmd, addCmd, removeCmd, useCmd)
+
+	return cmd
+}
+
+func (c *networkCmd) List(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	list := make(NetworkList, len(cfg.Networks))
+	for i, n := range cfg.Networks {
+		list[i] = &NetworkEntry{network: *n, Current: strings.EqualFold(n.Name, cfg.Current)}
+	}
+
+	return printResult(cmd, list)
+}
+
+func (c *networkCmd) Add(cmd *cobra.Command, args []string) error {
+	fUrl, err := cmd.Flags().GetString(flagNetworkUrl)
+	if err != nil {
+		return err
+	}
+	fChainId, err := cmd.Flags().GetUint64(flagNetworkChainId)
+	if err != nil {
+		return err
+	}
+	fExplorer, err := cmd.Flags().GetString(flagNetworkExplorer)
+	if err != nil {
+		return err
+	}
+	fCurrency, err := cmd.Flags().GetString(flagNetworkCurrency)
+	if err != nil {
+		return err
+	}
+	fDecimals, err := cmd.Flags().GetUint8(flagNetworkDecimals)
+	if err != nil {
+		return err
+	}
+
+	if _, err := url.ParseRequestURI(fUrl); err != nil {
+		return ErrInvalidRpcUrl
+	}
+	if fExplorer != "" {
+		if _, err := url.ParseRequestURI(fExplorer); err != nil {
+			return errors.New("error: please provide a valid explorer url (e.g. https://etherscan.io)")
+		}
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	cfg.addNetwork(&network{
+		Name:     args[0],
+		URL:      fUrl,
+		ChainID:  fChainId,
+		Explorer: fExplorer,
+		Currency: fCurrency,
+		Decimals: fDecimals,
+	})
+
+	return cfg.save()
+}
+
+func (c *networkCmd) Remove(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if !cfg.removeNetwork(args[0]) {
+		return fmt.Errorf("error: unknown network %q", args[0])
+	}
+
+	return cfg.save()
+}
+
+func (c *networkCmd) Use(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	n := cfg.network(args[0])
+	if n == nil {
+		return fmt.Errorf("error: unknown network %q", args[0])
+	}
+	cfg.Current = n.Name
+
+	return cfg.save()
+}
+
+// NetworkEntry is a configured network and whether it is the current one.
+type NetworkEntry struct {
+	network
+	Current bool `json:"current"`
+}
+
+// NetworkList is the list of the configured networks.
+type NetworkList []*NetworkEntry
+
+// String overrides the standard behavior for NetworkList "to-string", printing a table with the current network marked by a "*".
+func (l NetworkList) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tNAME\tCHAIN ID\tCURRENCY\tURL\tEXPLORER")
+	for _, n := range l {
+		current := ""
+		if n.Current {
+			current = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", current, n.Name, n.ChainID, n.Currency, n.URL, n.Explorer)
+	}
+	w.Flush()
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func execNetworkCmd(args string) (string, error) {
+	cmd := NewNetworkCmd()
+	actual := new(bytes.Buffer)
+	cmd.SetOut(actual)
+	cmd.SetErr(actual)
+	cmd.SetArgs(strings.Split(args, " "))
+	if err := cmd.Execute(); err != nil {
+		return "", err
+	}
+
+	return actual.String(), nil
+}
+
+func setupConfig(t *testing.T) {
+	t.Setenv(envConfig, filepath.Join(t.TempDir(), "config.yaml"))
+	t.Setenv(envRpcUrl, "")
+}
+
+func Test_NetworkCmd_AddUseRemove(t *testing.T) {
+	setupConfig(t)
+
+	_, err := execNetworkCmd("add sepolia --url https://nodes.sequence.app/sepolia --chain-id 11155111 --explorer https://sepolia.etherscan.io")
+	assert.Nil(t, err)
+	_, err = execNetworkCmd("add mainnet --url https://nodes.sequence.app/mainnet --chain-id 1")
+	assert.Nil(t, err)
+	_, err = execNetworkCmd("use sepolia")
+	assert.Nil(t, err)
+
+	cfg, err := loadConfig()
+	assert.Nil(t, err)
+	assert.Equal(t, "sepolia", cfg.Current)
+	assert.Len(t, cfg.Networks, 2)
+	assert.Equal(t, uint64(11155111), cfg.network("sepolia").ChainID)
+	assert.Equal(t, "ETH", cfg.network("sepolia").Currency)
+	assert.Equal(t, uint8(18), cfg.network("sepolia").Decimals)
+
+	res, err := execNetworkCmd("list")
+	assert.Nil(t, err)
+	assert.Contains(t, res, "https://nodes.sequence.app/sepolia")
+	assert.Contains(t, res, "https://nodes.sequence.app/mainnet")
+
+	// a current network edited by hand is matched regardless of case, like the network names
+	cfg.Current = "Sepolia"
+	assert.Nil(t, cfg.save())
+	res, err = execNetworkCmd("list")
+	assert.Nil(t, err)
+	assert.Contains(t, res, "*  sepolia")
+
+	_, err = execNetworkCmd("remove sepolia")
+	assert.Nil(t, err)
+
+	cfg, err = loadConfig()
+	assert.Nil(t, err)
+	assert.Empty(t, cfg.Current)
+	assert.Nil(t, cfg.network("sepolia"))
+}
+
+func Test_NetworkCmd_InvalidUrl(t *testing.T) {
+	setupConfig(t)
+
+	_, err := execNetworkCmd("add sepolia --url nodes.sequence.app/sepolia")
+	assert.Equal(t, ErrInvalidRpcUrl, err)
+}
+
+func Test_NetworkCmd_UseUnknown(t *testing.T) {
+	setupConfig(t)
+
+	_, err := execNetworkCmd("use unknown")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unknown network")
+}
+
+func Test_ResolveNetwork(t *testing.T) {
+	setupConfig(t)
+
+	cfg := &config{Current: "mainnet"}
+	cfg.addNetwork(&network{Name: "mainnet", URL: "https://nodes.sequence.app/mainnet"})
+	cfg.addNetwork(&network{Name: "sepolia", URL: "https://nodes.sequence.app/sepolia"})
+	assert.Nil(t, cfg.save())
+
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{Use: "test", RunE: func(*cobra.Command, []string) error { return nil }}
+		addRpcFlags(cmd)
+		cmd.Flags().StringP(flagNetwork, "n", "", "")
+		assert.Nil(t, cmd.ParseFlags(args))
+		return cmd
+	}
+
+	// default network from the config file
+	n, err := resolveNetwork(newCmd())
+	assert.Nil(t, err)
+	assert.Equal(t, "https://nodes.sequence.app/mainnet", n.URL)
+
+	// environment variable takes precedence over the config file
+	t.Setenv(envRpcUrl, "https://example.com/rpc")
+	n, err = resolveNetwork(newCmd())
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com/rpc", n.URL)
+
+	// --network takes precedence over the environment variable
+	n, err = resolveNetwork(newCmd("-n", "sepolia"))
+	assert.Nil(t, err)
+	assert.Equal(t, "sepolia", n.Name)
+
+	// --rpc-url takes precedence over everything else
+	n, err = resolveNetwork(newCmd("-n", "sepolia", "-r", "https://other.com/rpc"))
+	assert.Nil(t, err)
+	assert.Equal(t, "https://other.com/rpc", n.URL)
+
+	_, err = resolveNetwork(newCmd("-n", "unknown"))
+	assert.NotNil(t, err)
+
+	_, err = resolveNetwork(newCmd("-r", "not-a-url"))
+	assert.Equal(t, ErrInvalidRpcUrl, err)
+}
+
+func Test_ResolveNetwork_NotConfigured(t *testing.T) {
+	setupConfig(t)
+
+	cmd := &cobra.Command{Use: "test"}
+	addRpcFlags(cmd)
+	_, err := resolveNetwork(cmd)
+	assert.Equal(t, ErrNoRpcUrl, err)
+}
+
+func Test_Network_FormatAmount(t *testing.T) {
+	var n *network
+	assert.Equal(t, "0.5 ether", n.formatAmount(big.NewInt(500_000_000_000_000_000)))
+
+	n = &network{Currency: "POL", Decimals: 18}
+	assert.Equal(t, "0.5 POL", n.formatAmount(big.NewInt(500_000_000_000_000_000)))
+
+	n = &network{Currency: "USDC", Decimals: 6}
+	assert.Equal(t, "1.5 USDC", n.formatAmount(big.NewInt(1_500_000)))
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	flagOutput = "output"
+
+	// flagJsonAlias is the legacy --json flag of some commands, kept as an alias of --output json
+	flagJsonAlias = "json"
+)
+
+// output formats
+const (
+	outputTable    = "table"
+	outputJson     = "json"
+	outputJsonl    = "jsonl"
+	outputYaml     = "yaml"
+	outputCsv      = "csv"
+	outputTemplate = "template"
+)
+
+type outputFormat struct {
+	kind     string
+	template *template.Template
+}
+
+// parseOutputFormat parses the value of --output, which is one of table, json, jsonl, yaml, csv or template=<tmpl>.
+func parseOutputFormat(s string) (*outputFormat, error) {
+	kind, tmpl, hasTmpl := strings.Cut(s, "=")
+
+	switch kind {
+	case outputTable, outputJson, outputJsonl, outputYaml, outputCsv:
+		if hasTmpl {
+			return nil, fmt.Errorf("error: invalid output format %q", s)
+		}
+		return &outputFormat{kind: kind}, nil
+	case outputTemplate:
+		if !hasTmpl || tmpl == "" {
+			return nil, fmt.Errorf("error: please provide a template, e.g. --output 'template={{.hash}}'")
+		}
+		t, err := template.New("output").Funcs(template.FuncMap{
+			"json": func(v any) (string, error) {
+				b, err := json.Marshal(v)
+				return string(b), err
+			},
+		}).Parse(tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("error: invalid output template: %w", err)
+		}
+		return &outputFormat{kind: kind, template: t}, nil
+	default:
+		return nil, fmt.Errorf("error: unknown output format %q, supported: table, json, jsonl, yaml, csv, template=<tmpl>", s)
+	}
+}
+
+// outputFormatFor returns the output format of a command, as selected with --output or the legacy --json flag.
+func outputFormatFor(cmd *cobra.Command) (*outputFormat, error) {
+	value := outputTable
+	changed := false
+	if f := cmd.Flag(flagOutput); f != nil {
+		value, changed = f.Value.String(), f.Changed
+	}
+
+	if f := cmd.Flags().Lookup(flagJsonAlias); f != nil && f.Value.String() == "true" {
+		if changed && value != outputJson {
+			return nil, fmt.Errorf("error: --%s cannot be used with --%s %s", flagJsonAlias, flagOutput, value)
+		}
+		value = outputJson
+	}
+
+	return parseOutputFormat(value)
+}
+
+// printResult writes the result of a command to its output in the format selected with --output.
+func printResult(cmd *cobra.Command, result any) error {
+	format, err := outputFormatFor(cmd)
+	if err != nil {
+		return err
+	}
+
+	return render(cmd.OutOrStdout(), format, result)
+}
+
+// render writes a result in an output format. The table format uses the String method of the result
+// when available, every other format is based on the JSON encoding of the result.
+func render(w io.Writer, format *outputFormat, result any) error {
+	switch format.kind {
+	case outputJson:
+		json, err := PrettyJSON(result)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, *json)
+		return err
+	case outputJsonl:
+		return renderJsonl(w, result)
+	case outputTable:
+		return renderTable(w, result)
+	case outputTemplate:
+		return renderTemplate(w, format.template, result)
+	}
+
+	data, err := toOrdered(result)
+	if err != nil {
+		return err
+	}
+
+	switch format.kind {
+	case outputYaml:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(yamlNode(data)); err != nil {
+			return err
+		}
+		return enc.Close()
+	case outputCsv:
+		return renderCsv(w, data)
+	}
+
+	return fmt.Errorf("error: unknown output format %q", format.kind)
+}
+
+// toOrdered converts a result into its generic JSON representation, objects being Printable to keep the order of their keys.
+func toOrdered(result any) (any, error) {
+	b, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	return decodeOrdered(dec)
+}
+
+func renderTable(w io.Writer, result any) error {
+	if s, ok := result.(fmt.Stringer); ok {
+		_, err := fmt.Fprintln(w, s)
+		return err
+	}
+
+	data, err := toOrdered(result)
+	if err != nil {
+		return err
+	}
+	hexBytes(reflect.ValueOf(result), data)
+
+	switch t := data.(type) {
+	case *Printable:
+		_, err = fmt.Fprintln(w, t)
+		return err
+	case []any:
+		// a list of objects is printed as a table with a row per object
+		if len(t) > 0 {
+			if first, ok := t[0].(*Printable); ok {
+				tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+				header := make([]string, len(first.keys))
+				for i, k := range first.keys {
+					header[i] = strings.ToUpper(k)
+				}
+				fmt.Fprintln(tw, strings.Join(header, "\t"))
+				for _, elem := range t {
+					fmt.Fprintln(tw, strings.Join(rowValues(first.keys, elem), "\t"))
+				}
+				return tw.Flush()
+			}
+		}
+		// a list of values is printed with a value per line
+		for _, elem := range t {
+			if _, err := fmt.Fprintln(w, customFormat(elem)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	_, err = fmt.Fprintln(w, result)
+	return err
+}
+
+// renderTemplate executes a template with the JSON representation of a result, so that fields are referenced
+// by their JSON names, e.g. {{.hash}}.
+func renderTemplate(w io.Writer, tmpl *template.Template, result any) error {
+	b, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+
+	if err := tmpl.Execute(w, v); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w)
+	return err
+}
+
+func renderJsonl(w io.Writer, result any) error {
+	b, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	// a list is written as one line per element
+	lines := []json.RawMessage{b}
+	if len(b) > 0 && b[0] == '[' {
+		if err := json.Unmarshal(b, &lines); err != nil {
+			return err
+		}
+	}
+
+	for _, line := range lines {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, line); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, buf.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func renderCsv(w io.Writer, data any) error {
+	cw := csv.NewWriter(w)
+
+	switch t := data.(type) {
+	case *Printable:
+		cw.Write(t.keys)
+		cw.Write(rowValues(t.keys, t))
+	case []any:
+		var keys []string
+		if len(t) > 0 {
+			if first, ok := t[0].(*Printable); ok {
+				keys = first.keys
+				cw.Write(keys)
+			}
+		}
+		for _, elem := range t {
+			if keys != nil {
+				cw.Write(rowValues(keys, elem))
+			} else {
+				cw.Write([]string{csvValue(elem)})
+			}
+		}
+	default:
+		cw.Write([]string{csvValue(t)})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// rowValues returns the values of the keys of an object as strings, nested values being encoded as JSON.
+func rowValues(keys []string, elem any) []string {
+	obj, _ := elem.(*Printable)
+	row := make([]string, len(keys))
+	for i, k := range keys {
+		if obj == nil {
+			continue
+		}
+		v, _ := obj.Get(k)
+		row[i] = csvValue(v)
+	}
+	return row
+}
+
+func csvValue(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	case bool:
+		return strconv.FormatBool(t)
+	default:
+		b, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Sprint(t)
+		}
+		return string(b)
+	}
+}
+
+// yamlNode converts a generic JSON value into a yaml node, keeping the order of the object keys.
+func yamlNode(v any) *yaml.Node {
+	switch t := v.(type) {
+	case *Printable:
+		n := &yaml.Node{Kind: yaml.MappingNode}
+		for _, k := range t.keys {
+			n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}, yamlNode(t.values[k]))
+		}
+		return n
+	case []any:
+		n := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, elem := range t {
+			n.Content = append(n.Content, yamlNode(elem))
+		}
+		return n
+	case json.Number:
+		// numbers are left untagged as big integers would otherwise be written with an explicit !!int tag
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: t.String()}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(t)}
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: t}
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+}
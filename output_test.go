@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+type outputItem struct {
+	Name   string   `json:"name"`
+	Amount *big.Int `json:"amount"`
+	Tags   []string `json:"tags"`
+}
+
+var outputItems = []*outputItem{
+	{Name: "first", Amount: big.NewInt(1), Tags: []string{"a"}},
+	{Name: "second, with comma", Amount: new(big.Int).Lsh(big.NewInt(1), 80), Tags: []string{}},
+}
+
+func renderString(t *testing.T, format string, result any) string {
+	f, err := parseOutputFormat(format)
+	assert.Nil(t, err)
+	var buf bytes.Buffer
+	assert.Nil(t, render(&buf, f, result))
+	return buf.String()
+}
+
+func Test_ParseOutputFormat(t *testing.T) {
+	for _, s := range []string{"table", "json", "jsonl", "yaml", "csv", "template={{.name}}"} {
+		_, err := parseOutputFormat(s)
+		assert.Nil(t, err, s)
+	}
+	for _, s := range []string{"xml", "json=x", "template=", "template={{.name"} {
+		_, err := parseOutputFormat(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func Test_Render_Json(t *testing.T) {
+	assert.Equal(t, "{\n  \"name\": \"first\",\n  \"amount\": 1,\n  \"tags\": [\n    \"a\"\n  ]\n}\n", renderString(t, "json", outputItems[0]))
+}
+
+func Test_Render_Jsonl(t *testing.T) {
+	assert.Equal(t, `{"name":"first","amount":1,"tags":["a"]}
+{"name":"second, with comma","amount":1208925819614629174706176,"tags":[]}
+`, renderString(t, "jsonl", outputItems))
+}
+
+func Test_Render_Yaml(t *testing.T) {
+	assert.Equal(t, `- name: first
+  amount: 1
+  tags:
+    - a
+- name: second, with comma
+  amount: 1208925819614629174706176
+  tags: []
+`, renderString(t, "yaml", outputItems))
+}
+
+func Test_Render_Csv(t *testing.T) {
+	assert.Equal(t, `name,amount,tags
+first,1,"[""a""]"
+"second, with comma",1208925819614629174706176,[]
+`, renderString(t, "csv", outputItems))
+
+	assert.Equal(t, "name,amount,tags\nfirst,1,\"[\"\"a\"\"]\"\n", renderString(t, "csv", outputItems[0]))
+}
+
+func Test_Render_Template(t *testing.T) {
+	assert.Equal(t, "first 1 [\"a\"]\n", renderString(t, "template={{.name}} {{.amount}} {{json .tags}}", outputItems[0]))
+	assert.Equal(t, "first,second, with comma,\n", renderString(t, "template={{range .}}{{.name}},{{end}}", outputItems))
+}
+
+func Test_Render_Table(t *testing.T) {
+	// Stringer results are printed as is
+	balance := &Balance{Wei: big.NewInt(1000)}
+	assert.Equal(t, "1000 wei\n", renderString(t, "table", balance))
+
+	res := renderString(t, "table", outputItems)
+	rows := strings.Split(res, "\n")
+	assert.Equal(t, "NAME", strings.Fields(rows[0])[0])
+	assert.Contains(t, rows[2], "1208925819614629174706176")
+}
+
+func Test_OutputFormatFor(t *testing.T) {
+	cmd := &cobra.Command{Use: "test", RunE: func(cmd *cobra.Command, args []string) error { return nil }}
+	cmd.Flags().StringP(flagOutput, "o", outputTable, "")
+	cmd.Flags().BoolP(flagJsonAlias, "j", false, "")
+
+	assert.Nil(t, cmd.ParseFlags([]string{"-j"}))
+	f, err := outputFormatFor(cmd)
+	assert.Nil(t, err)
+	assert.Equal(t, outputJson, f.kind)
+
+	assert.Nil(t, cmd.ParseFlags([]string{"-j", "-o", "yaml"}))
+	_, err = outputFormatFor(cmd)
+	assert.NotNil(t, err)
+}
+
+func Test_NetworkCmd_ListOutput(t *testing.T) {
+	setupConfig(t)
+	_, err := execNetworkCmd("add mainnet --url https://nodes.sequence.app/mainnet --chain-id 1")
+	assert.Nil(t, err)
+
+	cmd := NewNetworkCmd()
+	cmd.PersistentFlags().StringP(flagOutput, "o", outputTable, "")
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"list", "-o", "jsonl"})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, `{"name":"mainnet","url":"https://nodes.sequence.app/mainnet","chainId":1,"currency":"ETH","decimals":18,"current":false}`+"\n", buf.String())
+}
+
+func Test_Balance_String(t *testing.T) {
+	b := &Balance{Account: common.Address{}, Wei: big.NewInt(500_000_000_000_000_000), inEther: true}
+	assert.Equal(t, "0.5 ether", b.String())
+}
+
+func Test_Render_TableValues(t *testing.T) {
+	v, err := GetValueByJSONTag(outputItems, "[*].name")
+	assert.Nil(t, err)
+	assert.Equal(t, "first\nsecond, with comma\n", renderString(t, "table", v))
+}
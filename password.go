@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagPasswordFile  = "password-file"
+	flagPasswordEnv   = "password-env"
+	flagPasswordStdin = "password-stdin"
+)
+
+// stdinReader is shared by every read of stdin, so that a mnemonic, passwords and confirmations can be piped
+// one per line without a reader buffering the input of the next one.
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// addPasswordFlags adds the flags selecting a non-interactive source for the key file passwords.
+func addPasswordFlags(flags *pflag.FlagSet) {
+	flags.String(flagPasswordFile, "", "Read the keyfile password from a file, one password per line (e.g. current and new password)")
+	flags.StringSlice(flagPasswordEnv, nil, "Read the keyfile password from an environment variable, several variables being read in order (e.g. CURRENT_PW,NEW_PW)")
+	flags.Bool(flagPasswordStdin, false, "Read the keyfile password from stdin, one password per line")
+}
+
+// passwordSource reads the key file passwords from the terminal, or from the source selected with
+// --password-file, --password-env or --password-stdin.
+type passwordSource struct {
+	file  string
+	envs  []string
+	stdin bool
+
+	// the passwords of a file or of environment variables, consumed in order
+	lines  []string
+	loaded bool
+
+	in   *bufio.Reader
+	warn io.Writer
+}
+
+// passwordSourceFor returns the password source of a command.
+func passwordSourceFor(cmd *cobra.Command) (*passwordSource, error) {
+	s := &passwordSource{in: stdinReader, warn: cmd.ErrOrStderr()}
+
+	set := 0
+	if f := cmd.Flag(flagPasswordFile); f != nil && f.Value.String() != "" {
+		s.file = f.Value.String()
+		set++
+	}
+	if f := cmd.Flag(flagPasswordEnv); f != nil {
+		if v, ok := f.Value.(pflag.SliceValue); ok && len(v.GetSlice()) > 0 {
+			s.envs = v.GetSlice()
+			set++
+		}
+	}
+	if f := cmd.Flag(flagPasswordStdin); f != nil && f.Value.String() == "true" {
+		s.stdin = true
+		set++
+	}
+	if set > 1 {
+		return nil, fmt.Errorf("error: please pass only one of --%s, --%s or --%s", flagPasswordFile, flagPasswordEnv, flagPasswordStdin)
+	}
+
+	return s, nil
+}
+
+// interactive returns whether the passwords are prompted on the terminal.
+func (s *passwordSource) interactive() bool {
+	return s.file == "" && len(s.envs) == 0 && !s.stdin
+}
+
+// read returns the next password, prompting for it on the terminal for an interactive source.
+func (s *passwordSource) read(prompt string) ([]byte, error) {
+	switch {
+	case s.interactive():
+		return readSecretInput(prompt)
+
+	case s.stdin:
+		line, err := s.in.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return nil, fmt.Errorf("error: could not read the password from stdin: %w", err)
+		}
+		return []byte(strings.TrimRight(line, "\r\n")), nil
+	}
+
+	if !s.loaded {
+		if err := s.load(); err != nil {
+			return nil, err
+		}
+	}
+	if len(s.lines) == 0 {
+		if s.file != "" {
+			return nil, fmt.Errorf("error: no more passwords in --%s %s, which should have one password per line", flagPasswordFile, s.file)
+		}
+		return nil, fmt.Errorf("error: no more passwords in --%s %s, which should list one environment variable per password", flagPasswordEnv, strings.Join(s.envs, ","))
+	}
+
+	pw := s.lines[0]
+	s.lines = s.lines[1:]
+	return []byte(pw), nil
+}
+
+func (s *passwordSource) load() error {
+	s.loaded = true
+
+	if len(s.envs) > 0 {
+		for _, env := range s.envs {
+			pw, ok := os.LookupEnv(env)
+			if !ok || pw == "" {
+				return fmt.Errorf("error: environment variable %s is not set", env)
+			}
+			s.lines = append(s.lines, pw)
+		}
+		return nil
+	}
+
+	info, err := os.Stat(s.file)
+	if err != nil {
+		return err
+	}
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		fmt.Fprintf(s.warn, "warning: password file %s is accessible by other users (mode %04o), consider chmod 600\n", s.file, perm)
+	}
+
+	data, err := os.ReadFile(s.file)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		if line != "" {
+			s.lines = append(s.lines, line)
+		}
+	}
+	if len(s.lines) == 0 {
+		return fmt.Errorf("error: password file %s is empty", s.file)
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPasswordSource(t *testing.T, args ...string) (*passwordSource, *bytes.Buffer, error) {
+	cmd := &cobra.Command{Use: "test", RunE: func(cmd *cobra.Command, args []string) error { return nil }}
+	addPasswordFlags(cmd.Flags())
+	warn := new(bytes.Buffer)
+	cmd.SetErr(warn)
+	assert.Nil(t, cmd.ParseFlags(args))
+
+	s, err := passwordSourceFor(cmd)
+	return s, warn, err
+}
+
+func Test_PasswordSource_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password")
+	assert.Nil(t, os.WriteFile(path, []byte("current password\r\nnew password\n"), 0600))
+
+	s, warn, err := newPasswordSource(t, "--password-file", path)
+	assert.Nil(t, err)
+	assert.False(t, s.interactive())
+
+	pw, err := s.read("Password: ")
+	assert.Nil(t, err)
+	assert.Equal(t, "current password", string(pw))
+	pw, err = s.read("Password: ")
+	assert.Nil(t, err)
+	assert.Equal(t, "new password", string(pw))
+	_, err = s.read("Password: ")
+	assert.NotNil(t, err)
+	assert.Empty(t, warn.String())
+}
+
+func Test_PasswordSource_FileWarning(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password")
+	assert.Nil(t, os.WriteFile(path, []byte("password\n"), 0600))
+	assert.Nil(t, os.Chmod(path, 0644))
+
+	s, warn, err := newPasswordSource(t, "--password-file", path)
+	assert.Nil(t, err)
+	_, err = s.read("Password: ")
+	assert.Nil(t, err)
+	assert.Contains(t, warn.String(), "warning: password file "+path+" is accessible by other users (mode 0644)")
+}
+
+func Test_PasswordSource_Env(t *testing.T) {
+	t.Setenv("ETHKIT_TEST_PASSWORD", "env password")
+
+	s, _, err := newPasswordSource(t, "--password-env", "ETHKIT_TEST_PASSWORD")
+	assert.Nil(t, err)
+	pw, err := s.read("Password: ")
+	assert.Nil(t, err)
+	assert.Equal(t, "env password", string(pw))
+
+	_, err = s.read("Password: ")
+	assert.EqualError(t, err, "error: no more passwords in --password-env ETHKIT_TEST_PASSWORD, which should list one environment variable per password")
+
+	s, _, err = newPasswordSource(t, "--password-env", "ETHKIT_TEST_MISSING")
+	assert.Nil(t, err)
+	_, err = s.read("Password: ")
+	assert.NotNil(t, err)
+}
+
+func Test_PasswordSource_EnvList(t *testing.T) {
+	t.Setenv("ETHKIT_TEST_PASSWORD", "current password")
+	t.Setenv("ETHKIT_TEST_NEW_PASSWORD", "new password")
+
+	for _, args := range [][]string{
+		{"--password-env", "ETHKIT_TEST_PASSWORD,ETHKIT_TEST_NEW_PASSWORD"},
+		{"--password-env", "ETHKIT_TEST_PASSWORD", "--password-env", "ETHKIT_TEST_NEW_PASSWORD"},
+	} {
+		s, _, err := newPasswordSource(t, args...)
+		assert.Nil(t, err)
+		pw, err := s.read("Password: ")
+		assert.Nil(t, err)
+		assert.Equal(t, "current password", string(pw))
+		pw, err = s.read("Password: ")
+		assert.Nil(t, err)
+		assert.Equal(t, "new password", string(pw))
+		_, err = s.read("Password: ")
+		assert.NotNil(t, err)
+	}
+}
+
+func Test_PasswordSource_Stdin(t *testing.T) {
+	s, _, err := newPasswordSource(t, "--password-stdin")
+	assert.Nil(t, err)
+	s.in = bufio.NewReader(strings.NewReader("first\nsecond"))
+
+	pw, err := s.read("Password: ")
+	assert.Nil(t, err)
+	assert.Equal(t, "first", string(pw))
+	pw, err = s.read("Password: ")
+	assert.Nil(t, err)
+	assert.Equal(t, "second", string(pw))
+	_, err = s.read("Password: ")
+	assert.NotNil(t, err)
+}
+
+func Test_PasswordSource_Conflict(t *testing.T) {
+	_, _, err := newPasswordSource(t, "--password-stdin", "--password-env", "PASSWORD")
+	assert.NotNil(t, err)
+
+	s, _, err := newPasswordSource(t)
+	assert.Nil(t, err)
+	assert.True(t, s.interactive())
+}
@@ -2,11 +2,14 @@ package main
 
 import (
 	"bytes"
+	"encoding"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"text/tabwriter"
@@ -24,8 +27,80 @@ func NewPrintableFormat(minwidth, tabwidth, padding int, padchar byte) *printabl
 	return &printableFormat{minwidth, tabwidth, padding, padchar}
 }
 
-// Printable is a generic key-value (map) structure that could contain nested objects.
-type Printable map[string]any
+// Printable is a generic key-value structure that could contain nested objects.
+// Keys are kept in the order they are set, which for FromStruct is the declared order of the struct fields.
+type Printable struct {
+	keys   []string
+	values map[string]any
+}
+
+// Keys returns the keys of the Printable in order.
+func (p *Printable) Keys() []string {
+	return p.keys
+}
+
+// Get returns the value of a key and whether the key is set.
+func (p *Printable) Get(key string) (any, bool) {
+	v, ok := p.values[key]
+	return v, ok
+}
+
+// Set sets the value of a key, appending the key if it is not set yet.
+func (p *Printable) Set(key string, value any) {
+	if p.values == nil {
+		p.values = make(map[string]any)
+	}
+	if _, ok := p.values[key]; !ok {
+		p.keys = append(p.keys, key)
+	}
+	p.values[key] = value
+}
+
+// Select returns a Printable with only the provided keys, in the provided order.
+// Keys are matched case-insensitively.
+func (p *Printable) Select(keys []string) (*Printable, error) {
+	sel := &Printable{}
+	for _, key := range keys {
+		found := false
+		for _, k := range p.keys {
+			if strings.EqualFold(k, key) {
+				sel.Set(k, p.values[k])
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, newFieldNotFoundError(key, p.keys)
+		}
+	}
+
+	return sel, nil
+}
+
+// MarshalJSON encodes the Printable as a JSON object preserving the order of its keys.
+func (p Printable) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range p.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(p.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
 
 // PrettyJSON prints an object in "prettified" JSON format
 func PrettyJSON(toJSON any) (*string, error) {
@@ -43,47 +118,120 @@ func PrettyJSON(toJSON any) (*string, error) {
 	return &jsonString, nil
 }
 
-// FromStruct converts a struct into a Printable using, when available, JSON field names as keys
+// FromStruct converts a struct into a Printable using, when available, JSON field names as keys.
+// Nested objects are converted into Printable too, and numbers are kept as json.Number to preserve their precision.
 func (p *Printable) FromStruct(input any) error {
-	bytes, err := json.Marshal(input)
+	b, err := json.Marshal(input)
 	if err != nil {
 		return err
 	}
-	if err := json.Unmarshal(bytes, &p); err != nil {
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	v, err := decodeOrdered(dec)
+	if err != nil {
 		return err
 	}
 
+	hexBytes(reflect.ValueOf(input), v)
+
+	switch t := v.(type) {
+	case nil:
+		*p = Printable{}
+	case *Printable:
+		*p = *t
+	default:
+		return fmt.Errorf("error: %T is not an object", input)
+	}
+
 	return nil
 }
 
+// decodeOrdered decodes the next JSON value, turning objects into Printable to keep the order of their keys.
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		obj := &Printable{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj.Set(key.(string), value)
+		}
+		// consume the closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case json.Delim('['):
+		list := []any{}
+		for dec.More() {
+			elem, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, elem)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return list, nil
+	default:
+		return tok, nil
+	}
+}
+
 // Columnize returns a formatted-in-columns (vertically aligned) string based on a provided configuration.
 func (p *Printable) Columnize(pf printableFormat) string {
 	var buf bytes.Buffer
 	w := tabwriter.NewWriter(&buf, pf.minwidth, pf.tabwidth, pf.padding, pf.padchar, tabwriter.Debug)
-	// NOTE: Order is not maintained whilst looping over map's . Results from different execution may differ.
-	for k, v := range *p {
-		printKeyValue(w, k, v)
+	for _, k := range p.keys {
+		printKeyValue(w, k, p.values[k])
 	}
 	w.Flush()
 
 	return buf.String()
 }
 
+// String overrides the standard behavior for Printable "to-string".
+func (p *Printable) String() string {
+	return p.Columnize(*NewPrintableFormat(20, 0, 0, byte(' ')))
+}
+
+// selectFields converts an object into a Printable with only the fields of a comma-separated list, in the listed order.
+func selectFields(obj any, fields string) (*Printable, error) {
+	var p Printable
+	if err := p.FromStruct(obj); err != nil {
+		return nil, err
+	}
+
+	return p.Select(splitFields(fields))
+}
+
 func printKeyValue(w *tabwriter.Writer, key string, value any) {
 	switch t := value.(type) {
-	// NOTE: Printable is not directly inferred as map[string]any therefore explicit reference is necessary
-	case map[string]any:
+	case *Printable:
 		fmt.Fprintln(w, key, "\t")
-		for tk, tv := range t {
-			printKeyValue(w, "\t "+tk, tv)
+		for _, tk := range t.keys {
+			printKeyValue(w, "\t "+tk, t.values[tk])
 		}
 	case []any:
 		fmt.Fprintln(w, key, "\t")
 		for _, elem := range t {
-			elemMap, ok := elem.(map[string]any)
+			elemObj, ok := elem.(*Printable)
 			if ok {
-				for tk, tv := range elemMap {
-					printKeyValue(w, "\t "+tk, tv)
+				for _, tk := range elemObj.keys {
+					printKeyValue(w, "\t "+tk, elemObj.values[tk])
 				}
 				fmt.Fprintln(w, "\t", "\t")
 			} else {
@@ -102,8 +250,10 @@ func customFormat(value any) string {
 		return fmt.Sprintf("%d", v)
 	case float32, float64:
 		return formatFloat(v)
+	case json.Number:
+		return formatFloat(v.String())
 	default:
-		return fmt.Sprintf("%v", base64ToHex(value))
+		return fmt.Sprintf("%v", value)
 	}
 }
 
@@ -118,62 +268,356 @@ func formatFloat(f any) string {
 	return str
 }
 
-func base64ToHex(str any) any {
-	_, ok := str.(string); if !ok {
-		return str
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// hexBytes replaces, in the generic JSON representation of a value, the base64 encoding of its []byte fields by
+// their 0x-prefixed hex, the format of bytes in the table output. Only the []byte fields are decoded, the strings
+// which happen to be valid base64, such as "safe", are left unchanged.
+func hexBytes(v reflect.Value, data any) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if hasCustomJSON(v.Type()) {
+		return
 	}
-	decoded, err := base64.StdEncoding.DecodeString(str.(string))
+
+	switch v.Kind() {
+	case reflect.Struct:
+		obj, ok := data.(*Printable)
+		if !ok {
+			return
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			name, _, _ := strings.Cut(tag, ",")
+			if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+				continue
+			}
+			// the fields of an embedded struct are encoded as fields of the outer object
+			if f.Anonymous && name == "" {
+				hexBytes(v.Field(i), obj)
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			value, ok := obj.Get(name)
+			if !ok {
+				continue
+			}
+			if isBase64Bytes(f.Type) {
+				if str, ok := value.(string); ok {
+					if decoded, err := base64.StdEncoding.DecodeString(str); err == nil {
+						obj.values[name] = "0x" + hex.EncodeToString(decoded)
+					}
+				}
+				continue
+			}
+			hexBytes(v.Field(i), value)
+		}
+
+	case reflect.Slice, reflect.Array:
+		list, ok := data.([]any)
+		if !ok {
+			return
+		}
+		for i := 0; i < v.Len() && i < len(list); i++ {
+			hexBytes(v.Index(i), list[i])
+		}
+
+	case reflect.Map:
+		obj, ok := data.(*Printable)
+		if !ok || v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		for _, k := range obj.keys {
+			if value := v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key())); value.IsValid() {
+				hexBytes(value, obj.values[k])
+			}
+		}
+	}
+}
+
+// hasCustomJSON returns whether a type is encoded by its own MarshalJSON or MarshalText, e.g. hexutil.Bytes.
+func hasCustomJSON(t reflect.Type) bool {
+	for _, m := range []reflect.Type{jsonMarshalerType, textMarshalerType} {
+		if t.Implements(m) || reflect.PointerTo(t).Implements(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBase64Bytes returns whether a type is a byte slice encoded in base64 by encoding/json.
+func isBase64Bytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && !hasCustomJSON(t) && !hasCustomJSON(t.Elem())
+}
+
+// GetValueByJSONTag returns the value of a field of an object, referenced by a path of JSON field names such as
+// "transactions[0].hash", "withdrawals[*].amount" or "uncles.length". Field names are case-insensitive and, when
+// not found at the top level, the first field of the path is searched in the nested objects.
+// Multiple comma-separated paths return an object with a key per path.
+func GetValueByJSONTag(input any, jsonTag string) (any, error) {
+	paths := splitFields(jsonTag)
+	if len(paths) == 0 {
+		return nil, errors.New("error: please provide a field")
+	}
+
+	data, err := toOrdered(input)
 	if err != nil {
-		return str
+		return nil, err
+	}
+
+	if len(paths) == 1 {
+		return valueAtPath(data, paths[0])
+	}
+
+	res := &Printable{}
+	for _, path := range paths {
+		v, err := valueAtPath(data, path)
+		if err != nil {
+			return nil, err
+		}
+		res.Set(path, v)
 	}
 
-	return "0x" + hex.EncodeToString(decoded)
+	return res, nil
+}
+
+// splitFields splits a comma-separated list of fields.
+func splitFields(s string) []string {
+	var fields []string
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
 }
 
-// GetValueByJSONTag returns the value of a struct field matching a JSON tag provided in input.
-func GetValueByJSONTag(input any, jsonTag string) any {
-	// TODO: Refactor to support both nil values and errors when key not found
-	return findField(reflect.ValueOf(input), jsonTag)
+// pathStep is a step of a field path: a field name, an index or all the elements ([*]) of a list.
+type pathStep struct {
+	field string
+	index int
+	all   bool
 }
 
-func findField(val reflect.Value, jsonTag string) any {
-	seen := make(map[uintptr]bool)
+// parsePath parses a field path such as "transactions[0].hash" into its steps.
+func parsePath(path string) ([]pathStep, error) {
+	var steps []pathStep
 
-	// take the value the pointer val points to
-	if val.Kind() == reflect.Ptr {
-		val = val.Elem()
+	for _, segment := range strings.Split(path, ".") {
+		name, rest, hasIndex := strings.Cut(segment, "[")
+		if name == "" && !hasIndex {
+			return nil, fmt.Errorf("error: invalid field path %q", path)
+		}
+		if name != "" {
+			steps = append(steps, pathStep{field: name})
+		}
+
+		for hasIndex {
+			idx, after, ok := strings.Cut(rest, "]")
+			if !ok || (after != "" && after[0] != '[') {
+				return nil, fmt.Errorf("error: invalid field path %q", path)
+			}
+			if idx == "*" {
+				steps = append(steps, pathStep{all: true})
+			} else {
+				n, err := strconv.Atoi(idx)
+				if err != nil {
+					return nil, fmt.Errorf("error: invalid index %q in field path %q", idx, path)
+				}
+				steps = append(steps, pathStep{index: n})
+			}
+			rest, hasIndex = strings.CutPrefix(after, "[")
+		}
+	}
+
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("error: invalid field path %q", path)
 	}
 
-	// return if the element is not a struct
-	if val.Kind() != reflect.Struct {
-		return nil
+	return steps, nil
+}
+
+// valueAtPath returns the value at a field path of a generic JSON value.
+func valueAtPath(data any, path string) (any, error) {
+	steps, err := parsePath(path)
+	if err != nil {
+		return nil, err
 	}
 
-	// check if the struct has already been processed to avoid infinite recursion
-	if val.CanAddr() {
-		ptr := val.Addr().Pointer()
-		if seen[ptr] {
-			return nil
+	v, err := walkPath(data, steps)
+
+	// a field missing at the top level is searched in the nested objects
+	var notFound *fieldNotFoundError
+	if errors.As(err, &notFound) && steps[0].field != "" {
+		if obj := findNestedField(data, steps[0].field); obj != nil {
+			return walkPath(obj, steps)
 		}
-		seen[ptr] = true
 	}
 
-	t := val.Type()
+	return v, err
+}
 
-	for i := 0; i < val.NumField(); i++ {
-		field := t.Field(i)
-		tag := field.Tag.Get("json")
+func walkPath(v any, steps []pathStep) (any, error) {
+	for i, step := range steps {
+		switch {
+		case step.field != "":
+			var err error
+			if v, err = fieldValue(v, step.field); err != nil {
+				return nil, err
+			}
+		case step.all:
+			list, ok := v.([]any)
+			if !ok {
+				return nil, errors.New("error: [*] can only be applied to a list")
+			}
+			res := make([]any, len(list))
+			for j, elem := range list {
+				r, err := walkPath(elem, steps[i+1:])
+				if err != nil {
+					return nil, err
+				}
+				res[j] = r
+			}
+			return res, nil
+		default:
+			list, ok := v.([]any)
+			if !ok {
+				return nil, fmt.Errorf("error: [%d] can only be applied to a list", step.index)
+			}
+			idx := step.index
+			if idx < 0 {
+				idx += len(list)
+			}
+			if idx < 0 || idx >= len(list) {
+				return nil, fmt.Errorf("error: index %d out of range, the list has %d elements", step.index, len(list))
+			}
+			v = list[idx]
+		}
+	}
+
+	return v, nil
+}
 
-		fieldValue := val.Field(i)
-		if fieldValue.Kind() == reflect.Struct {
-			// recursively process fields including embedded ones
-			return findField(fieldValue, jsonTag)
-		} else {
-			if strings.EqualFold(strings.ToLower(tag), strings.ToLower(jsonTag)) {
-				return val.Field(i).Interface()
+// fieldValue returns the value of a field of an object, or the length of a list, object or string for "length".
+func fieldValue(v any, field string) (any, error) {
+	if obj, ok := v.(*Printable); ok {
+		for _, k := range obj.keys {
+			if strings.EqualFold(k, field) {
+				return obj.values[k], nil
 			}
 		}
 	}
 
+	if strings.EqualFold(field, "length") {
+		switch t := v.(type) {
+		case *Printable:
+			return json.Number(strconv.Itoa(len(t.keys))), nil
+		case []any:
+			return json.Number(strconv.Itoa(len(t))), nil
+		case string:
+			return json.Number(strconv.Itoa(len(t))), nil
+		}
+	}
+
+	switch t := v.(type) {
+	case *Printable:
+		return nil, newFieldNotFoundError(field, t.keys)
+	case []any:
+		return nil, fmt.Errorf("error: field %q is not available on a list, use an index such as [0] or [*]", field)
+	default:
+		return nil, fmt.Errorf("error: field %q is not available on a %T value", field, v)
+	}
+}
+
+// findNestedField returns the first nested object, in depth-first order, having a field.
+func findNestedField(v any, field string) *Printable {
+	switch t := v.(type) {
+	case *Printable:
+		for _, k := range t.keys {
+			if strings.EqualFold(k, field) {
+				return t
+			}
+		}
+		for _, k := range t.keys {
+			if obj := findNestedField(t.values[k], field); obj != nil {
+				return obj
+			}
+		}
+	case []any:
+		for _, elem := range t {
+			if obj := findNestedField(elem, field); obj != nil {
+				return obj
+			}
+		}
+	}
 	return nil
 }
+
+// fieldNotFoundError is returned when a field is not found, suggesting the closest available fields.
+type fieldNotFoundError struct {
+	field       string
+	available   []string
+	suggestions []string
+}
+
+func newFieldNotFoundError(field string, available []string) *fieldNotFoundError {
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var candidates []candidate
+	for _, a := range available {
+		d := levenshtein(strings.ToLower(field), strings.ToLower(a))
+		if d <= len(field)/3+1 || strings.Contains(strings.ToLower(a), strings.ToLower(field)) {
+			candidates = append(candidates, candidate{a, d})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	e := &fieldNotFoundError{field: field, available: available}
+	for i := 0; i < len(candidates) && i < 3; i++ {
+		e.suggestions = append(e.suggestions, candidates[i].name)
+	}
+	return e
+}
+
+func (e *fieldNotFoundError) Error() string {
+	if len(e.suggestions) > 0 {
+		return fmt.Sprintf("error: field %q not found, did you mean: %s?", e.field, strings.Join(e.suggestions, ", "))
+	}
+	return fmt.Sprintf("error: field %q not found, available fields: %s", e.field, strings.Join(e.available, ", "))
+}
+
+// levenshtein returns the edit distance between two strings.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
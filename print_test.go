@@ -1,11 +1,14 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -85,11 +88,148 @@ func Test_Columnize(t *testing.T) {
 func Test_GetValueByJSONTag(t *testing.T) {
 	setup()
 	tag := "title"
-	assert.Equal(t, GetValueByJSONTag(complex, tag), complex.Nested.Title)
+	v, err := GetValueByJSONTag(complex, tag)
+	assert.Nil(t, err)
+	assert.Equal(t, v, complex.Nested.Title)
 }
 
 func Test_GetValueByJSONTag_FailWhenNotStruct(t *testing.T) {
 	setup()
 	tag := "title"
-	assert.Nil(t, GetValueByJSONTag(p, tag))
+	_, err := GetValueByJSONTag([]string{"first"}, tag)
+	assert.NotNil(t, err)
+}
+
+func Test_GetValueByJSONTag_Paths(t *testing.T) {
+	setup()
+	for path, expected := range map[string]any{
+		"nested.title":     "hello",
+		"NESTED.Value":     json.Number("500000000"),
+		"list[1]":          "second",
+		"list[-1]":         "second",
+		"list.length":      json.Number("2"),
+		"objList[0].item2": json.Number("2"),
+		"object.length":    json.Number("2"),
+		"name.length":      json.Number("7"),
+		"objList[1].item5": json.Number("21234560"),
+	} {
+		v, err := GetValueByJSONTag(complex, path)
+		assert.Nil(t, err, path)
+		assert.Equal(t, expected, v, path)
+	}
+
+	v, err := GetValueByJSONTag(complex, "objList[*].length")
+	assert.Nil(t, err)
+	assert.Equal(t, []any{json.Number("2"), json.Number("3")}, v)
+
+	// elements missing the field make the whole selection fail
+	_, err = GetValueByJSONTag(complex, "objList[*].item1")
+	assert.ErrorContains(t, err, `field "item1" not found`)
+}
+
+func Test_GetValueByJSONTag_MultipleFields(t *testing.T) {
+	setup()
+	v, err := GetValueByJSONTag(complex, "name, nested.value")
+	assert.Nil(t, err)
+	obj := v.(*Printable)
+	assert.Equal(t, []string{"name", "nested.value"}, obj.Keys())
+	value, _ := obj.Get("nested.value")
+	assert.Equal(t, json.Number("500000000"), value)
+}
+
+func Test_GetValueByJSONTag_Errors(t *testing.T) {
+	setup()
+	_, err := GetValueByJSONTag(complex, "nmae")
+	assert.EqualError(t, err, `error: field "nmae" not found, did you mean: name?`)
+
+	_, err = GetValueByJSONTag(complex, "invalid")
+	assert.EqualError(t, err, `error: field "invalid" not found, available fields: name, list, nested, object, objList`)
+
+	_, err = GetValueByJSONTag(complex, "list[2]")
+	assert.ErrorContains(t, err, "out of range")
+
+	_, err = GetValueByJSONTag(complex, "list.first")
+	assert.ErrorContains(t, err, "use an index")
+
+	for _, path := range []string{"", "list[", "list[a]", "list[0]x", "name..length"} {
+		_, err = GetValueByJSONTag(complex, path)
+		assert.NotNil(t, err, path)
+	}
+}
+
+func Test_Columnize_KeepsFieldOrder(t *testing.T) {
+	setup()
+	assert.Equal(t, []string{"name", "list", "nested", "object", "objList"}, p.Keys())
+
+	// nested objects keep the struct order, the row of each key must follow the previous one
+	last := -1
+	for _, k := range []string{"name", "list", "nested", "title", "value", "object", "objList"} {
+		i := strings.Index(s, k)
+		assert.Greater(t, i, last, k)
+		last = i
+	}
+
+	for i := 0; i < 5; i++ {
+		var q Printable
+		_ = q.FromStruct(complex)
+		assert.Equal(t, s, q.Columnize(*NewPrintableFormat(minwidth, tabwidth, padding, padchar)))
+	}
+}
+
+func Test_Printable_Select(t *testing.T) {
+	setup()
+	sel, err := p.Select([]string{"NESTED", "name"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"nested", "name"}, sel.Keys())
+
+	_, err = p.Select([]string{"missing"})
+	assert.ErrorContains(t, err, "available fields: name, list, nested, object, objList")
+}
+
+func Test_Printable_MarshalJSON(t *testing.T) {
+	sel, err := selectFields(struct {
+		B  string   `json:"b"`
+		A  Nested   `json:"a"`
+		Id *big.Int `json:"id"`
+	}{B: "b", A: Nested{Title: "t", Value: 1}, Id: new(big.Int).Lsh(big.NewInt(1), 100)}, "id, a,b")
+	assert.Nil(t, err)
+
+	b, err := json.Marshal(sel)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"id":1267650600228229401496703205376,"a":{"title":"t","value":1},"b":"b"}`, string(b))
+	assert.Contains(t, sel.String(), "1267650600228229401496703205376")
+}
+
+func Test_Columnize_HexBytes(t *testing.T) {
+	type inner struct {
+		Extra []byte `json:"extra"`
+	}
+	obj := struct {
+		Name   string        `json:"name"`
+		Kind   string        `json:"kind"`
+		Extra  []byte        `json:"extraData"`
+		Data   hexutil.Bytes `json:"data"`
+		Nested []inner       `json:"nested"`
+	}{
+		Name:   "safe",
+		Kind:   "mnemonic",
+		Extra:  []byte{0xde, 0xad},
+		Data:   hexutil.Bytes{0xbe, 0xef},
+		Nested: []inner{{Extra: []byte{0x01}}},
+	}
+
+	var q Printable
+	assert.Nil(t, q.FromStruct(obj))
+	out := q.String()
+	// strings which happen to be valid base64 are printed as they are
+	assert.Contains(t, out, "safe")
+	assert.Contains(t, out, "mnemonic")
+	assert.Contains(t, out, "0xdead")
+	assert.Contains(t, out, "0xbeef")
+	assert.Contains(t, out, "0x01")
+
+	var buf strings.Builder
+	assert.Nil(t, renderTable(&buf, &obj))
+	assert.Contains(t, buf.String(), "mnemonic")
+	assert.Contains(t, buf.String(), "0xdead")
 }
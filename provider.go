@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+)
+
+const (
+	flagRpcUrl  = "rpc-url"
+	flagNetwork = "network"
+)
+
+// addRpcFlags registers the flags needed by a command talking to a blockchain node.
+// The --network flag is persistent on the root command and is therefore inherited.
+func addRpcFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP(flagRpcUrl, "r", "", "The RPC endpoint to the blockchain node to interact with")
+}
+
+// resolveNetwork returns the network a command should interact with. In order of precedence
+// it is built from the --rpc-url flag, the --network flag, the ETHKIT_RPC_URL environment
+// variable and lastly the default network of the config file.
+func resolveNetwork(cmd *cobra.Command) (*network, error) {
+	var fRpc, fNetwork string
+	if f := cmd.Flag(flagRpcUrl); f != nil {
+		fRpc = f.Value.String()
+	}
+	if f := cmd.Flag(flagNetwork); f != nil {
+		fNetwork = f.Value.String()
+	}
+
+	var n *network
+	switch {
+	case fRpc != "":
+		n = &network{URL: fRpc}
+	case fNetwork != "":
+		cfg, err := loadConfig()
+		if err != nil {
+			return nil, err
+		}
+		if n = cfg.network(fNetwork); n == nil {
+			return nil, fmt.Errorf("error: unknown network %q, see `ethkit network list`", fNetwork)
+		}
+	case os.Getenv(envRpcUrl) != "":
+		n = &network{URL: os.Getenv(envRpcUrl)}
+	default:
+		cfg, err := loadConfig()
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Current == "" {
+			return nil, ErrNoRpcUrl
+		}
+		if n = cfg.network(cfg.Current); n == nil {
+			return nil, fmt.Errorf("error: default network %q is not defined, see `ethkit network list`", cfg.Current)
+		}
+	}
+
+	if _, err := url.ParseRequestURI(n.URL); err != nil {
+		return nil, ErrInvalidRpcUrl
+	}
+
+	return n, nil
+}
+
+// newProvider returns an RPC provider for the network resolved from the command flags,
+// the environment and the config file.
+func newProvider(cmd *cobra.Command) (*ethrpc.Provider, *network, error) {
+	n, err := resolveNetwork(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	provider, err := ethrpc.NewProvider(n.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return provider, n, nil
+}
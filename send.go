@@ -0,0 +1,408 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/ethtxn"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+)
+
+const (
+	flagSendKeyFile     = "keyfile"
+	flagSendPath        = "path"
+	flagSendTo          = "to"
+	flagSendValue       = "value"
+	flagSendData        = "data"
+	flagSendAbi         = "abi"
+	flagSendNonce       = "nonce"
+	flagSendGasLimit    = "gas-limit"
+	flagSendMaxFee      = "max-fee"
+	flagSendPriorityFee = "priority-fee"
+	flagSendGasPrice    = "gas-price"
+	flagSendLegacy      = "legacy"
+	flagSendDryRun      = "dry-run"
+	flagSendWait        = "wait"
+	flagSendYes         = "yes"
+)
+
+func init() {
+	rootCmd.AddCommand(NewSendCmd())
+}
+
+type send struct {
+}
+
+// NewSendCmd returns a new command to sign and broadcast a transaction from a wallet key file.
+func NewSendCmd() *cobra.Command {
+	c := &send{}
+	cmd := &cobra.Command{
+		Use:   "send [signature|method] [args...]",
+		Short: "Sign and broadcast a transaction from a wallet key file",
+		Long: "Sign and broadcast a transaction from a wallet key file.\n\n" +
+			"The nonce, gas limit, fees and chain ID are filled from the network unless provided. A contract method\n" +
+			"can be called by passing its human-readable signature and arguments, or its name along with --abi.",
+		Example: `  ethkit send --keyfile wallet.json --to 0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742 --value 0.1ether
+  ethkit send --keyfile wallet.json --to 0x1c7D4B196Cb0C7B01d743Fbc6116a902379C7238 "transfer(address,uint256)" 0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742 1000 --wait`,
+		RunE: c.Run,
+	}
+
+	cmd.Flags().String(flagSendKeyFile, "", "wallet key file path (required)")
+	cmd.Flags().String(flagSendPath, "", "derivation path, default: the path stored in the key file")
+	cmd.Flags().String(flagSendTo, "", "The recipient address, leave empty to deploy a contract with --data")
+	cmd.Flags().String(flagSendValue, "0", "The amount to send (e.g. 0.1ether, 30gwei, 1000wei)")
+	cmd.Flags().String(flagSendData, "", "raw 0x-prefixed calldata, used instead of a method and its arguments")
+	cmd.Flags().String(flagSendAbi, "", "path to a contract artifacts or abi json file")
+	addABIFlags(cmd.Flags())
+	cmd.Flags().Int64(flagSendNonce, -1, "The nonce of the transaction, default: the pending nonce of the sender")
+	cmd.Flags().Uint64(flagSendGasLimit, 0, "The gas limit of the transaction, default: estimated")
+	cmd.Flags().String(flagSendMaxFee, "", "The max fee per gas (e.g. 30gwei), default: twice the base fee plus the priority fee")
+	cmd.Flags().String(flagSendPriorityFee, "", "The max priority fee per gas (e.g. 1gwei), default: suggested by the node")
+	cmd.Flags().String(flagSendGasPrice, "", "The gas price of a legacy transaction (e.g. 30gwei), default: suggested by the node")
+	cmd.Flags().Bool(flagSendLegacy, false, "Send a legacy (type 0) transaction instead of an EIP-1559 one")
+	cmd.Flags().Bool(flagSendDryRun, false, "Only print the signed raw transaction, without broadcasting it")
+	cmd.Flags().Bool(flagSendWait, false, "Wait for the transaction receipt")
+	cmd.Flags().BoolP(flagSendYes, "y", false, "Skip the confirmation prompt")
+	addPasswordFlags(cmd.Flags())
+	addRpcFlags(cmd)
+
+	return cmd
+}
+
+func (c *send) Run(cmd *cobra.Command, args []string) error {
+	fKeyFile, err := cmd.Flags().GetString(flagSendKeyFile)
+	if err != nil {
+		return err
+	}
+	fPath, err := cmd.Flags().GetString(flagSendPath)
+	if err != nil {
+		return err
+	}
+	fTo, err := cmd.Flags().GetString(flagSendTo)
+	if err != nil {
+		return err
+	}
+	fValue, err := cmd.Flags().GetString(flagSendValue)
+	if err != nil {
+		return err
+	}
+	fData, err := cmd.Flags().GetString(flagSendData)
+	if err != nil {
+		return err
+	}
+	abiSrc, err := abiSourceFor(cmd, flagSendAbi)
+	if err != nil {
+		return err
+	}
+	fNonce, err := cmd.Flags().GetInt64(flagSendNonce)
+	if err != nil {
+		return err
+	}
+	fGasLimit, err := cmd.Flags().GetUint64(flagSendGasLimit)
+	if err != nil {
+		return err
+	}
+	fMaxFee, err := cmd.Flags().GetString(flagSendMaxFee)
+	if err != nil {
+		return err
+	}
+	fPriorityFee, err := cmd.Flags().GetString(flagSendPriorityFee)
+	if err != nil {
+		return err
+	}
+	fGasPrice, err := cmd.Flags().GetString(flagSendGasPrice)
+	if err != nil {
+		return err
+	}
+	fLegacy, err := cmd.Flags().GetBool(flagSendLegacy)
+	if err != nil {
+		return err
+	}
+	fDryRun, err := cmd.Flags().GetBool(flagSendDryRun)
+	if err != nil {
+		return err
+	}
+	fWait, err := cmd.Flags().GetBool(flagSendWait)
+	if err != nil {
+		return err
+	}
+	fYes, err := cmd.Flags().GetBool(flagSendYes)
+	if err != nil {
+		return err
+	}
+
+	if fKeyFile == "" {
+		return errors.New("error: please pass --keyfile")
+	}
+	passwords, err := passwordSourceFor(cmd)
+	if err != nil {
+		return err
+	}
+
+	var to *common.Address
+	if fTo != "" {
+		if !common.IsHexAddress(fTo) {
+			return errors.New("error: please provide a valid --to address")
+		}
+		addr := common.HexToAddress(fTo)
+		to = &addr
+	}
+
+	value, err := parseEtherValue(fValue)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	switch {
+	case fData != "" && len(args) > 0:
+		return errors.New("error: a method and its arguments cannot be passed along with --data")
+	case fData != "":
+		if data, err = hexutil.Decode(fData); err != nil {
+			return errors.New("error: please provide a valid 0x-prefixed hex --data")
+		}
+	case len(args) > 0:
+		method, err := resolveMethod(args[0], abiSrc)
+		if err != nil {
+			return err
+		}
+		if data, err = encodeCalldata(method, args[1:]); err != nil {
+			return err
+		}
+	}
+	if to == nil && len(data) == 0 {
+		return errors.New("error: please pass --to, or --data to deploy a contract")
+	}
+
+	if fLegacy && (fMaxFee != "" || fPriorityFee != "") {
+		return fmt.Errorf("error: --%s and --%s cannot be used with --%s, use --%s instead", flagSendMaxFee, flagSendPriorityFee, flagSendLegacy, flagSendGasPrice)
+	}
+	if !fLegacy && fGasPrice != "" {
+		return fmt.Errorf("error: --%s requires --%s", flagSendGasPrice, flagSendLegacy)
+	}
+
+	txnRequest := &ethtxn.TransactionRequest{
+		To:       to,
+		ETHValue: value,
+		Data:     data,
+		GasLimit: fGasLimit,
+	}
+	if fNonce >= 0 {
+		txnRequest.Nonce = big.NewInt(fNonce)
+	}
+
+	keyFile, err := readWalletKeyFile(fKeyFile)
+	if err != nil {
+		return err
+	}
+
+	provider, network, err := newProvider(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	chainID, err := provider.ChainID(ctx)
+	if err != nil {
+		return err
+	}
+	if network.ChainID != 0 && network.ChainID != chainID.Uint64() {
+		return fmt.Errorf("error: the node chain ID %s does not match the chain ID %d of network %q", chainID, network.ChainID, network.Name)
+	}
+
+	if fLegacy {
+		if fGasPrice != "" {
+			if txnRequest.GasPrice, err = parseEtherValue(fGasPrice); err != nil {
+				return err
+			}
+		}
+	} else {
+		if err := fillDynamicFees(ctx, provider, txnRequest, fMaxFee, fPriorityFee); err != nil {
+			return err
+		}
+	}
+
+	wallet, err := keyFile.unlock(passwords, fPath)
+	if err != nil {
+		return err
+	}
+	txnRequest.From = wallet.Address()
+
+	rawTx, err := ethtxn.NewTransaction(ctx, provider, txnRequest)
+	if err != nil {
+		return err
+	}
+
+	signedTx, err := wallet.SignTx(rawTx, chainID)
+	if err != nil {
+		return err
+	}
+
+	if fDryRun {
+		raw, err := signedTx.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		return printResult(cmd, &SendResult{Hash: signedTx.Hash(), Raw: raw})
+	}
+
+	summary := NewSendSummary(wallet.Address(), signedTx, network)
+	fmt.Fprintln(cmd.ErrOrStderr(), summary)
+
+	if !fYes {
+		answer, err := readPlainInput("Send transaction? [y/N]: ")
+		if err != nil {
+			return err
+		}
+		if a := strings.ToLower(strings.TrimSpace(string(answer))); a != "y" && a != "yes" {
+			return errors.New("error: transaction cancelled")
+		}
+	}
+
+	if err := provider.SendTransaction(ctx, signedTx); err != nil {
+		return err
+	}
+
+	res := &SendResult{Hash: signedTx.Hash()}
+
+	if !fWait {
+		return printResult(cmd, res)
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "transaction %s sent, waiting for the receipt...\n", signedTx.Hash().Hex())
+
+	if _, err := ethrpc.WaitForTxnReceipt(ctx, provider, signedTx.Hash()); err != nil {
+		return err
+	}
+	rawReceipt, err := rawTransactionReceipt(ctx, provider, signedTx.Hash())
+	if err != nil {
+		return err
+	}
+	res.Receipt, err = NewReceipt(rawReceipt, network)
+	if err != nil {
+		return err
+	}
+
+	if err := printResult(cmd, res); err != nil {
+		return err
+	}
+
+	if res.Receipt.Status != "success" {
+		return fmt.Errorf("error: transaction %s failed", signedTx.Hash().Hex())
+	}
+
+	return nil
+}
+
+// fillDynamicFees sets the EIP-1559 fees of a transaction request. The priority fee defaults to the one
+// suggested by the node, and the max fee to twice the latest base fee plus the priority fee.
+func fillDynamicFees(ctx context.Context, provider *ethrpc.Provider, txnRequest *ethtxn.TransactionRequest, maxFee, priorityFee string) error {
+	var err error
+
+	if priorityFee != "" {
+		if txnRequest.GasTip, err = parseEtherValue(priorityFee); err != nil {
+			return err
+		}
+	} else {
+		if txnRequest.GasTip, err = provider.SuggestGasTipCap(ctx); err != nil {
+			return err
+		}
+	}
+
+	if maxFee != "" {
+		if txnRequest.GasPrice, err = parseEtherValue(maxFee); err != nil {
+			return err
+		}
+	} else {
+		head, err := provider.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if head.BaseFee == nil {
+			return fmt.Errorf("error: the network does not support EIP-1559 transactions, please use --%s", flagSendLegacy)
+		}
+		txnRequest.GasPrice = new(big.Int).Add(new(big.Int).Mul(head.BaseFee, big.NewInt(2)), txnRequest.GasTip)
+	}
+
+	if txnRequest.GasPrice.Cmp(txnRequest.GasTip) < 0 {
+		return fmt.Errorf("error: --%s must be greater than or equal to --%s", flagSendMaxFee, flagSendPriorityFee)
+	}
+
+	return nil
+}
+
+// SendSummary is the summary of a signed transaction shown before broadcasting it.
+type SendSummary struct {
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to"`
+	Value                string          `json:"value"`
+	Data                 hexutil.Bytes   `json:"data"`
+	ChainID              *big.Int        `json:"chainId"`
+	Type                 string          `json:"type"`
+	Nonce                uint64          `json:"nonce"`
+	GasLimit             uint64          `json:"gasLimit"`
+	GasPrice             *big.Int        `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *big.Int        `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *big.Int        `json:"maxPriorityFeePerGas,omitempty"`
+	MaxCost              string          `json:"maxCost"`
+}
+
+// NewSendSummary returns the summary of a signed transaction, its amounts in the native currency of the network.
+func NewSendSummary(from common.Address, tx *types.Transaction, network *network) *SendSummary {
+	s := &SendSummary{
+		From:     from,
+		To:       tx.To(),
+		Value:    network.formatAmount(tx.Value()),
+		Data:     tx.Data(),
+		ChainID:  tx.ChainId(),
+		Type:     txTypeName(tx.Type()),
+		Nonce:    tx.Nonce(),
+		GasLimit: tx.Gas(),
+		MaxCost:  network.formatAmount(tx.Cost()),
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		s.MaxFeePerGas = tx.GasFeeCap()
+		s.MaxPriorityFeePerGas = tx.GasTipCap()
+	} else {
+		s.GasPrice = tx.GasPrice()
+	}
+	return s
+}
+
+// String overrides the standard behavior for SendSummary "to-string".
+func (s *SendSummary) String() string {
+	var p Printable
+	if err := p.FromStruct(s); err != nil {
+		panic(err)
+	}
+	return p.Columnize(*NewPrintableFormat(24, 0, 0, byte(' ')))
+}
+
+// SendResult is the result of the send command: the hash of the broadcast transaction and, with --wait, its
+// receipt, or with --dry-run the signed raw transaction.
+type SendResult struct {
+	Hash    common.Hash   `json:"hash"`
+	Raw     hexutil.Bytes `json:"raw,omitempty"`
+	Receipt *Receipt      `json:"receipt,omitempty"`
+}
+
+// String overrides the standard behavior for SendResult "to-string".
+func (r *SendResult) String() string {
+	switch {
+	case r.Raw != nil:
+		return r.Raw.String()
+	case r.Receipt != nil:
+		return r.Hash.Hex() + "\n" + r.Receipt.String()
+	default:
+		return r.Hash.Hex()
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func execSendCmd(args string) (string, error) {
+	cmd := NewSendCmd()
+	actual := new(bytes.Buffer)
+	cmd.SetOut(actual)
+	cmd.SetErr(actual)
+	cmd.SetArgs(strings.Split(args, " "))
+	if err := cmd.Execute(); err != nil {
+		return "", err
+	}
+
+	return actual.String(), nil
+}
+
+func Test_ParseEtherValue(t *testing.T) {
+	for s, expected := range map[string]string{
+		"0":            "0",
+		"1000":         "1000",
+		"1000wei":      "1000",
+		"30gwei":       "30000000000",
+		"1.5 gwei":     "1500000000",
+		"0.1ether":     "100000000000000000",
+		"2ETH":         "2000000000000000000",
+		"0.000000001e": "",
+		"-1ether":      "",
+		"0.5wei":       "",
+		"ether":        "",
+	} {
+		v, err := parseEtherValue(s)
+		if expected == "" {
+			assert.NotNil(t, err, s)
+			continue
+		}
+		assert.Nil(t, err, s)
+		assert.Equal(t, expected, v.String(), s)
+	}
+}
+
+func Test_SendCmd_InvalidArgs(t *testing.T) {
+	_, err := execSendCmd("--to 0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742")
+	assert.ErrorContains(t, err, "--keyfile")
+
+	_, err = execSendCmd("--keyfile wallet.json --to 0x1234")
+	assert.ErrorContains(t, err, "--to address")
+
+	_, err = execSendCmd("--keyfile wallet.json")
+	assert.ErrorContains(t, err, "please pass --to")
+
+	_, err = execSendCmd("--keyfile wallet.json --to 0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742 --value 1.5foo")
+	assert.ErrorContains(t, err, "invalid amount")
+
+	_, err = execSendCmd("--keyfile wallet.json --to 0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742 --data 0x1234 transfer(address,uint256)")
+	assert.ErrorContains(t, err, "--data")
+
+	_, err = execSendCmd("--keyfile wallet.json --to 0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742 --legacy --max-fee 30gwei")
+	assert.ErrorContains(t, err, "--legacy")
+
+	_, err = execSendCmd("--keyfile wallet.json --to 0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742 --gas-price 30gwei")
+	assert.ErrorContains(t, err, "requires --legacy")
+}
+
+func Test_NewSendSummary(t *testing.T) {
+	to := common.HexToAddress("0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742")
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     7,
+		GasTipCap: big.NewInt(1e9),
+		GasFeeCap: big.NewInt(3e9),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(1e17),
+	})
+
+	summary := NewSendSummary(common.Address{}, tx, nil)
+	assert.Equal(t, "0.1 ether", summary.Value)
+	assert.Equal(t, "0.100063 ether", summary.MaxCost)
+	assert.Equal(t, big.NewInt(3e9), summary.MaxFeePerGas)
+	assert.Nil(t, summary.GasPrice)
+	assert.Contains(t, summary.String(), "maxPriorityFeePerGas")
+}
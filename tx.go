@@ -0,0 +1,383 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/go-ethereum"
+	"github.com/0xsequence/ethkit/go-ethereum/accounts/abi"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+)
+
+const (
+	flagTxField   = "field"
+	flagTxFields  = "fields"
+	flagTxReceipt = "receipt"
+	flagTxJson    = "json"
+	flagTxAbi     = "abi"
+)
+
+var (
+	ErrInvalidTxHash = errors.New("error: please provide a valid 32-byte transaction hash")
+	ErrTxNotFound    = errors.New("transaction not found")
+)
+
+func init() {
+	rootCmd.AddCommand(NewTxCmd())
+}
+
+type transaction struct {
+}
+
+// NewTxCmd returns a new command to retrieve a transaction and, optionally, its receipt.
+func NewTxCmd() *cobra.Command {
+	c := &transaction{}
+	cmd := &cobra.Command{
+		Use:     "tx [hash]",
+		Short:   "Get the information about a transaction",
+		Aliases: []string{"transaction"},
+		Args:    cobra.ExactArgs(1),
+		RunE:    c.Run,
+	}
+
+	cmd.Flags().StringP(flagTxField, "f", "", "Get a field by its path, e.g. from, receipt.status, receipt.logs[*].address (comma-separated for multiple fields)")
+	cmd.Flags().String(flagTxFields, "", "Comma-separated list of the fields to print, in order (e.g. hash,from,to,value)")
+	cmd.Flags().Bool(flagTxReceipt, false, "Include the transaction receipt")
+	cmd.Flags().String(flagTxAbi, "", "path to a contract artifacts or abi json file to decode the calldata and logs with")
+	addABIFlags(cmd.Flags())
+	addRpcFlags(cmd)
+	cmd.Flags().BoolP(flagTxJson, "j", false, "Print as JSON, alias of --output json")
+
+	return cmd
+}
+
+func (c *transaction) Run(cmd *cobra.Command, args []string) error {
+	fHash := cmd.Flags().Args()[0]
+	fField, err := cmd.Flags().GetString(flagTxField)
+	if err != nil {
+		return err
+	}
+	fFields, err := cmd.Flags().GetString(flagTxFields)
+	if err != nil {
+		return err
+	}
+	fReceipt, err := cmd.Flags().GetBool(flagTxReceipt)
+	if err != nil {
+		return err
+	}
+	abiSrc, err := abiSourceFor(cmd, flagTxAbi)
+	if err != nil {
+		return err
+	}
+
+	if fField != "" && fFields != "" {
+		return errors.New("error: --field and --fields cannot be used together")
+	}
+
+	if b, err := hexutil.Decode(fHash); err != nil || len(b) != common.HashLength {
+		return ErrInvalidTxHash
+	}
+
+	provider, network, err := newProvider(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	hash := common.HexToHash(fHash)
+
+	rawTx, err := rawTransactionByHash(ctx, provider, hash)
+	if err != nil {
+		return err
+	}
+	tx, err := NewTransaction(rawTx)
+	if err != nil {
+		return err
+	}
+	if network.Explorer != "" {
+		tx.Explorer = strings.TrimSuffix(network.Explorer, "/") + "/tx/" + tx.Hash.Hex()
+	}
+
+	if fReceipt {
+		rawReceipt, err := rawTransactionReceipt(ctx, provider, hash)
+		if err != nil {
+			return err
+		}
+		tx.Receipt, err = NewReceipt(rawReceipt, network)
+		if err != nil {
+			return err
+		}
+	}
+
+	if abiSrc != nil {
+		contractABI, err := loadABI(abiSrc)
+		if err != nil {
+			return err
+		}
+		tx.decode(contractABI)
+	}
+
+	var obj any = tx
+
+	if fField != "" {
+		if obj, err = GetValueByJSONTag(obj, fField); err != nil {
+			return err
+		}
+	}
+
+	if fFields != "" {
+		if obj, err = selectFields(obj, fFields); err != nil {
+			return err
+		}
+	}
+
+	return printResult(cmd, obj)
+}
+
+func rawTransactionByHash(ctx context.Context, provider *ethrpc.Provider, hash common.Hash) (json.RawMessage, error) {
+	var raw json.RawMessage
+	call := ethrpc.NewCallBuilder[json.RawMessage]("eth_getTransactionByHash", nil, hash).Into(&raw)
+	if _, err := provider.Do(ctx, call); err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, ErrTxNotFound
+	}
+	return raw, nil
+}
+
+func rawTransactionReceipt(ctx context.Context, provider *ethrpc.Provider, hash common.Hash) (json.RawMessage, error) {
+	var raw json.RawMessage
+	call := ethrpc.NewCallBuilder[json.RawMessage]("eth_getTransactionReceipt", nil, hash).Into(&raw)
+	if _, err := provider.Do(ctx, call); err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, errors.New("transaction receipt not found, the transaction may still be pending")
+	}
+	return raw, nil
+}
+
+// Transaction is a customized transaction for cli.
+type Transaction struct {
+	Hash                 common.Hash     `json:"hash"`
+	Type                 string          `json:"type"`
+	BlockHash            *common.Hash    `json:"blockHash"`
+	BlockNumber          *big.Int        `json:"blockNumber"`
+	TransactionIndex     *uint64         `json:"transactionIndex"`
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to"`
+	Nonce                uint64          `json:"nonce"`
+	Value                *big.Int        `json:"value"`
+	Gas                  uint64          `json:"gas"`
+	GasPrice             *big.Int        `json:"gasPrice,omitempty"`
+	MaxPriorityFeePerGas *big.Int        `json:"maxPriorityFeePerGas,omitempty"`
+	MaxFeePerGas         *big.Int        `json:"maxFeePerGas,omitempty"`
+	Input                hexutil.Bytes   `json:"input"`
+	DecodedInput         *DecodedCall    `json:"decodedInput,omitempty"`
+	ChainID              *big.Int        `json:"chainId,omitempty"`
+	V                    *big.Int        `json:"v"`
+	R                    *big.Int        `json:"r"`
+	S                    *big.Int        `json:"s"`
+	Explorer             string          `json:"explorer,omitempty"`
+	Receipt              *Receipt        `json:"receipt,omitempty"`
+}
+
+// NewTransaction returns the custom-built Transaction object from an eth_getTransactionByHash result.
+// The sender is recovered from the transaction signature.
+func NewTransaction(raw json.RawMessage) (*Transaction, error) {
+	var tx *types.Transaction
+	if err := ethrpc.IntoTransaction(raw, &tx); err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return nil, ErrTxNotFound
+		}
+		return nil, err
+	}
+
+	var inclusion struct {
+		BlockHash        *common.Hash    `json:"blockHash"`
+		BlockNumber      *hexutil.Big    `json:"blockNumber"`
+		TransactionIndex *hexutil.Uint64 `json:"transactionIndex"`
+	}
+	if err := json.Unmarshal(raw, &inclusion); err != nil {
+		return nil, err
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, err
+	}
+
+	v, r, s := tx.RawSignatureValues()
+
+	t := &Transaction{
+		Hash:      tx.Hash(),
+		Type:      txTypeName(tx.Type()),
+		BlockHash: inclusion.BlockHash,
+		From:      from,
+		To:        tx.To(),
+		Nonce:     tx.Nonce(),
+		Value:     tx.Value(),
+		Gas:       tx.Gas(),
+		Input:     tx.Data(),
+		V:         v,
+		R:         r,
+		S:         s,
+	}
+	if inclusion.BlockNumber != nil {
+		t.BlockNumber = inclusion.BlockNumber.ToInt()
+	}
+	if inclusion.TransactionIndex != nil {
+		index := uint64(*inclusion.TransactionIndex)
+		t.TransactionIndex = &index
+	}
+	if tx.Protected() {
+		t.ChainID = tx.ChainId()
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		t.MaxPriorityFeePerGas = tx.GasTipCap()
+		t.MaxFeePerGas = tx.GasFeeCap()
+	} else {
+		t.GasPrice = tx.GasPrice()
+	}
+
+	return t, nil
+}
+
+// String overrides the standard behavior for Transaction "to-string".
+func (t *Transaction) String() string {
+	var p Printable
+	if err := p.FromStruct(t); err != nil {
+		panic(err)
+	}
+	s := p.Columnize(*NewPrintableFormat(24, 0, 0, byte(' ')))
+
+	return s
+}
+
+// decode decodes the calldata and, when the receipt is available, the logs of the transaction.
+// Calldata and logs not matching the abi, e.g. logs emitted by other contracts, are left undecoded.
+func (t *Transaction) decode(contractABI abi.ABI) {
+	if call, err := decodeCalldata(contractABI, t.Input); err == nil {
+		t.DecodedInput = call
+	}
+
+	if t.Receipt == nil {
+		return
+	}
+	for _, log := range t.Receipt.Logs {
+		if event, err := decodeLog(contractABI, log.Topics, log.Data); err == nil {
+			log.Event = event
+		}
+	}
+}
+
+// txTypeName returns the human-readable name of an EIP-2718 transaction type.
+func txTypeName(t uint8) string {
+	switch t {
+	case types.LegacyTxType:
+		return "legacy"
+	case types.AccessListTxType:
+		return "access-list (EIP-2930)"
+	case types.DynamicFeeTxType:
+		return "dynamic-fee (EIP-1559)"
+	default:
+		return fmt.Sprintf("unknown (%d)", t)
+	}
+}
+
+// Receipt is a customized transaction receipt for cli.
+type Receipt struct {
+	Status            string          `json:"status"`
+	BlockHash         common.Hash     `json:"blockHash"`
+	BlockNumber       *big.Int        `json:"blockNumber"`
+	TransactionIndex  uint            `json:"transactionIndex"`
+	GasUsed           uint64          `json:"gasUsed"`
+	CumulativeGasUsed uint64          `json:"cumulativeGasUsed"`
+	EffectiveGasPrice *big.Int        `json:"effectiveGasPrice"`
+	Fee               string          `json:"fee"`
+	ContractAddress   *common.Address `json:"contractAddress"`
+	Logs              []*Log          `json:"logs"`
+}
+
+// NewReceipt returns the custom-built Receipt object from an eth_getTransactionReceipt result, its fee in the native
+// currency of the network.
+func NewReceipt(raw json.RawMessage, network *network) (*Receipt, error) {
+	var receipt types.Receipt
+	if err := json.Unmarshal(raw, &receipt); err != nil {
+		return nil, err
+	}
+
+	// NOTE: effectiveGasPrice is not decoded by go-ethereum's receipt unmarshaler
+	var extra struct {
+		EffectiveGasPrice *hexutil.Big `json:"effectiveGasPrice"`
+	}
+	if err := json.Unmarshal(raw, &extra); err != nil {
+		return nil, err
+	}
+
+	r := &Receipt{
+		Status:            "failure",
+		BlockHash:         receipt.BlockHash,
+		BlockNumber:       receipt.BlockNumber,
+		TransactionIndex:  receipt.TransactionIndex,
+		GasUsed:           receipt.GasUsed,
+		CumulativeGasUsed: receipt.CumulativeGasUsed,
+		Logs:              make([]*Log, len(receipt.Logs)),
+	}
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		r.Status = "success"
+	}
+	if receipt.ContractAddress != (common.Address{}) {
+		r.ContractAddress = &receipt.ContractAddress
+	}
+	if extra.EffectiveGasPrice != nil {
+		r.EffectiveGasPrice = extra.EffectiveGasPrice.ToInt()
+		fee := new(big.Int).Mul(r.EffectiveGasPrice, new(big.Int).SetUint64(r.GasUsed))
+		r.Fee = network.formatAmount(fee)
+	}
+	for i, log := range receipt.Logs {
+		r.Logs[i] = NewLog(log)
+	}
+
+	return r, nil
+}
+
+// String overrides the standard behavior for Receipt "to-string".
+func (r *Receipt) String() string {
+	var p Printable
+	if err := p.FromStruct(r); err != nil {
+		panic(err)
+	}
+	s := p.Columnize(*NewPrintableFormat(24, 0, 0, byte(' ')))
+
+	return s
+}
+
+// Log is a customized event log for cli.
+type Log struct {
+	Index   uint           `json:"logIndex"`
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+	Event   *DecodedEvent  `json:"event,omitempty"`
+}
+
+// NewLog returns the custom-built Log object.
+func NewLog(l *types.Log) *Log {
+	return &Log{
+		Index:   l.Index,
+		Address: l.Address,
+		Topics:  l.Topics,
+		Data:    l.Data,
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/core/types"
+	"github.com/0xsequence/ethkit/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/0xsequence/ethkit-cli/internal/rpctest"
+)
+
+func execTxCmd(args string) (string, error) {
+	cmd := NewTxCmd()
+	actual := new(bytes.Buffer)
+	cmd.SetOut(actual)
+	cmd.SetErr(actual)
+	cmd.SetArgs(strings.Split(args, " "))
+	if err := cmd.Execute(); err != nil {
+		return "", err
+	}
+
+	return actual.String(), nil
+}
+
+func Test_TxCmd_InvalidHash(t *testing.T) {
+	res, err := execTxCmd("0x1234 --rpc-url https://nodes.sequence.app/sepolia")
+	assert.Equal(t, ErrInvalidTxHash, err)
+	assert.Empty(t, res)
+}
+
+func Test_TxCmd_InvalidRpcUrl(t *testing.T) {
+	res, err := execTxCmd("0x97e5c24dc2fd74f6e56773a0ad1cf29fe403130ca6ec1dd10ff8828d72b0a352 --rpc-url nodes.sequence.app/sepolia")
+	assert.Equal(t, ErrInvalidRpcUrl, err)
+	assert.Empty(t, res)
+}
+
+func Test_NewTransaction(t *testing.T) {
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	to := common.HexToAddress("0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742")
+	chainID := big.NewInt(11155111)
+
+	signed, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     7,
+		GasTipCap: big.NewInt(1_000_000_000),
+		GasFeeCap: big.NewInt(30_000_000_000),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(500_000_000_000_000_000),
+	})
+	assert.Nil(t, err)
+
+	data, err := signed.MarshalJSON()
+	assert.Nil(t, err)
+	var raw map[string]any
+	assert.Nil(t, json.Unmarshal(data, &raw))
+	raw["blockHash"] = "0x97e5c24dc2fd74f6e56773a0ad1cf29fe403130ca6ec1dd10ff8828d72b0a352"
+	raw["blockNumber"] = "0x11fb59d"
+	raw["transactionIndex"] = "0x3"
+	data, _ = json.Marshal(raw)
+
+	tx, err := NewTransaction(data)
+	assert.Nil(t, err)
+	assert.Equal(t, signed.Hash(), tx.Hash)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), tx.From)
+	assert.Equal(t, "dynamic-fee (EIP-1559)", tx.Type)
+	assert.Equal(t, big.NewInt(18855325), tx.BlockNumber)
+	assert.Equal(t, uint64(3), *tx.TransactionIndex)
+	assert.Equal(t, chainID, tx.ChainID)
+	assert.Nil(t, tx.GasPrice)
+	assert.Equal(t, big.NewInt(30_000_000_000), tx.MaxFeePerGas)
+}
+
+func Test_NewReceipt(t *testing.T) {
+	raw := `{
+		"blockHash": "0x97e5c24dc2fd74f6e56773a0ad1cf29fe403130ca6ec1dd10ff8828d72b0a352",
+		"blockNumber": "0x11fb59d",
+		"contractAddress": null,
+		"cumulativeGasUsed": "0x5208",
+		"effectiveGasPrice": "0x3b9aca00",
+		"from": "0x213a286a1af3ac010d4f2d66a52deaf762df7742",
+		"gasUsed": "0x5208",
+		"logs": [],
+		"logsBloom": "0x` + strings.Repeat("0", 512) + `",
+		"status": "0x1",
+		"to": "0x213a286a1af3ac010d4f2d66a52deaf762df7742",
+		"transactionHash": "0x97e5c24dc2fd74f6e56773a0ad1cf29fe403130ca6ec1dd10ff8828d72b0a352",
+		"transactionIndex": "0x0",
+		"type": "0x2"
+	}`
+
+	r, err := NewReceipt(json.RawMessage(raw), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "success", r.Status)
+	assert.Equal(t, uint64(21000), r.GasUsed)
+	assert.Equal(t, big.NewInt(1_000_000_000), r.EffectiveGasPrice)
+	assert.Equal(t, "0.000021 ether", r.Fee)
+	assert.Nil(t, r.ContractAddress)
+}
+
+func Test_TxCmd_FieldAndFields(t *testing.T) {
+	res, err := execTxCmd("0x97e5c24dc2fd74f6e56773a0ad1cf29fe403130ca6ec1dd10ff8828d72b0a352 -f hash --fields hash,from")
+	assert.ErrorContains(t, err, "cannot be used together")
+	assert.Empty(t, res)
+}
+
+func Test_TxCmd_Receipt(t *testing.T) {
+	srv := rpctest.NewServer(t, "testdata/rpc/tx.json")
+	path := writeABIFile(t)
+	res, err := execTxCmd("0xdeaca0c85cb4784430f6cea150c67e3ff7dc199b787ff57a30d41a0f376919fe --receipt --abi " + path + " --rpc-url " + srv.URL + " --json")
+	assert.Nil(t, err)
+
+	var tx Transaction
+	assert.Nil(t, json.Unmarshal([]byte(res), &tx))
+	assert.Equal(t, common.HexToAddress("0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"), tx.From)
+	assert.Equal(t, "transfer(address,uint256)", tx.DecodedInput.Signature)
+	assert.Equal(t, "success", tx.Receipt.Status)
+	assert.Equal(t, "Transfer", tx.Receipt.Logs[0].Event.Event)
+}
+
+func Test_TxCmd_Field(t *testing.T) {
+	srv := rpctest.NewServer(t, "testdata/rpc/tx.json")
+	res, err := execTxCmd("0xdeaca0c85cb4784430f6cea150c67e3ff7dc199b787ff57a30d41a0f376919fe --receipt --rpc-url " + srv.URL + " -f receipt.status,nonce")
+	assert.Nil(t, err)
+	assert.Contains(t, res, "success")
+	assert.Contains(t, res, "42")
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/0xsequence/ethkit/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagTypedDataAddress   = "address"
+	flagTypedDataSignature = "signature"
+)
+
+func init() {
+	rootCmd.AddCommand(NewTypedDataCmd())
+}
+
+type typedDataCmd struct {
+}
+
+// NewTypedDataCmd returns a new command to hash and verify EIP-712 typed structured data.
+func NewTypedDataCmd() *cobra.Command {
+	c := &typedDataCmd{}
+	cmd := &cobra.Command{
+		Use:   "typed-data",
+		Short: "Hash and verify EIP-712 typed structured data",
+	}
+
+	hashCmd := &cobra.Command{
+		Use:     "hash [file]",
+		Short:   "Print the domain separator, struct hash and digest of an eth_signTypedData_v4 JSON file, - for stdin",
+		Example: `  ethkit typed-data hash permit.json`,
+		Args:    cobra.ExactArgs(1),
+		RunE:    c.Hash,
+	}
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify [file]",
+		Short: "Recover the signer of the signature of an eth_signTypedData_v4 JSON file, - for stdin",
+		Example: `  ethkit typed-data verify permit.json --signature 0x...
+  ethkit typed-data verify permit.json --signature 0x... --address 0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266`,
+		Args: cobra.ExactArgs(1),
+		RunE: c.Verify,
+	}
+	verifyCmd.Flags().String(flagTypedDataSignature, "", "The 65 bytes 0x-prefixed signature, with a v of 27/28 or 0/1 (required)")
+	verifyCmd.Flags().String(flagTypedDataAddress, "", "The address of the expected signer, the command failing on mismatch")
+
+	cmd.AddCommand(hashCmd, verifyCmd)
+
+	return cmd
+}
+
+func (c *typedDataCmd) Hash(cmd *cobra.Command, args []string) error {
+	td, err := readTypedData(args[0])
+	if err != nil {
+		return err
+	}
+
+	domainSeparator, structHash, digest, err := td.digest()
+	if err != nil {
+		return err
+	}
+
+	return printResult(cmd, &TypedDataHash{
+		PrimaryType:     td.PrimaryType,
+		DomainSeparator: domainSeparator,
+		StructHash:      structHash,
+		Digest:          digest,
+	})
+}
+
+func (c *typedDataCmd) Verify(cmd *cobra.Command, args []string) error {
+	fSignature, err := cmd.Flags().GetString(flagTypedDataSignature)
+	if err != nil {
+		return err
+	}
+	fAddress, err := cmd.Flags().GetString(flagTypedDataAddress)
+	if err != nil {
+		return err
+	}
+
+	signature, err := hexutil.Decode(fSignature)
+	if err != nil || len(signature) != crypto.SignatureLength {
+		return errors.New("error: please provide a valid --signature of 65 bytes, 0x-prefixed")
+	}
+	if fAddress != "" && !common.IsHexAddress(fAddress) {
+		return errors.New("error: please provide a valid --address (e.g. 0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742)")
+	}
+
+	td, err := readTypedData(args[0])
+	if err != nil {
+		return err
+	}
+	_, _, digest, err := td.digest()
+	if err != nil {
+		return err
+	}
+
+	signer, err := recoverSigner(digest, signature)
+	if err != nil {
+		return err
+	}
+
+	// without an expected address, the recovered signer is printed
+	address := signer
+	if fAddress != "" {
+		address = common.HexToAddress(fAddress)
+	}
+	res := &MessageVerification{Address: address, Signer: signer, Valid: signer == address}
+	if err := printResult(cmd, res); err != nil {
+		return err
+	}
+
+	if !res.Valid {
+		return fmt.Errorf("error: the typed data was not signed by %s", address.Hex())
+	}
+
+	return nil
+}
+
+// TypedDataHash are the hashes of EIP-712 typed data, the digest being the hash to sign.
+type TypedDataHash struct {
+	PrimaryType     string        `json:"primaryType"`
+	DomainSeparator hexutil.Bytes `json:"domainSeparator"`
+	StructHash      hexutil.Bytes `json:"structHash"`
+	Digest          hexutil.Bytes `json:"digest"`
+}
+
+// String overrides the standard behavior for TypedDataHash "to-string".
+func (h *TypedDataHash) String() string {
+	var p Printable
+	if err := p.FromStruct(h); err != nil {
+		panic(err)
+	}
+	return p.Columnize(*NewPrintableFormat(20, 0, 0, byte(' ')))
+}
+
+// TypedDataSignature is the signature of EIP-712 typed data.
+type TypedDataSignature struct {
+	Address         common.Address `json:"address"`
+	PrimaryType     string         `json:"primaryType"`
+	DomainSeparator hexutil.Bytes  `json:"domainSeparator"`
+	StructHash      hexutil.Bytes  `json:"structHash"`
+	Digest          hexutil.Bytes  `json:"digest"`
+	Signature       hexutil.Bytes  `json:"signature"`
+}
+
+// String overrides the standard behavior for TypedDataSignature "to-string".
+func (s *TypedDataSignature) String() string {
+	var p Printable
+	if err := p.FromStruct(s); err != nil {
+		panic(err)
+	}
+	// the address is printed with its EIP-55 checksum rather than as the lowercase hex of its JSON encoding
+	p.Set("address", s.Address.Hex())
+	return p.Columnize(*NewPrintableFormat(20, 0, 0, byte(' ')))
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/0xsequence/ethkit/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// the example of EIP-712, signed with the private key keccak256("cow")
+var mailTypedDataFile = filepath.Join("testdata", "typed_data", "mail.json")
+
+const mailSignature = "0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b915621c"
+
+func execTypedDataCmd(args string) (string, error) {
+	cmd := NewTypedDataCmd()
+	actual := new(bytes.Buffer)
+	cmd.SetOut(actual)
+	cmd.SetErr(actual)
+	cmd.SetArgs(strings.Split(args, " "))
+	if err := cmd.Execute(); err != nil {
+		return "", err
+	}
+
+	return actual.String(), nil
+}
+
+func Test_TypedData_Digest(t *testing.T) {
+	td, err := readTypedData(mailTypedDataFile)
+	assert.Nil(t, err)
+
+	encodedType, err := td.encodeType("Mail")
+	assert.Nil(t, err)
+	assert.Equal(t, "Mail(Person from,Person to,string contents)Person(string name,address wallet)", encodedType)
+
+	domainSeparator, structHash, digest, err := td.digest()
+	assert.Nil(t, err)
+	assert.Equal(t, "0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f", hexutil.Encode(domainSeparator))
+	assert.Equal(t, "0xc52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e", hexutil.Encode(structHash))
+	assert.Equal(t, "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2", hexutil.Encode(digest))
+
+	key, err := crypto.ToECDSA(crypto.Keccak256([]byte("cow")))
+	assert.Nil(t, err)
+	sig, err := crypto.Sign(digest, key)
+	assert.Nil(t, err)
+	sig[64] += 27
+	assert.Equal(t, mailSignature, hexutil.Encode(sig))
+}
+
+func Test_TypedData_EncodeValue(t *testing.T) {
+	td, err := parseTypedData([]byte(`{
+		"types": {"Order": [{"name": "amounts", "type": "int8[]"}, {"name": "tag", "type": "bytes4"}]},
+		"primaryType": "Order",
+		"domain": {"name": "Orders", "chainId": "0x1"},
+		"message": {"amounts": [-1, "0x7f"], "tag": "0x01020304"}
+	}`))
+	assert.Nil(t, err)
+
+	// the domain type is built from the domain fields
+	encodedType, err := td.encodeType(eip712Domain)
+	assert.Nil(t, err)
+	assert.Equal(t, "EIP712Domain(string name,uint256 chainId)", encodedType)
+
+	word, err := td.encodeValue("int8", "-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "0x"+strings.Repeat("ff", 32), hexutil.Encode(word))
+
+	for typ, value := range map[string]any{
+		"int8":    "128",
+		"uint8":   "-1",
+		"bytes4":  "0x01",
+		"address": "0x01",
+		"bool":    "maybe",
+		"int8[2]": []any{"1"},
+		"uint7":   "1",
+	} {
+		_, err := td.encodeValue(typ, value)
+		assert.NotNil(t, err, typ)
+	}
+
+	_, _, _, err = td.digest()
+	assert.Nil(t, err)
+
+	delete(td.Message, "tag")
+	_, _, _, err = td.digest()
+	assert.NotNil(t, err)
+}
+
+func Test_TypedDataCmd_Hash(t *testing.T) {
+	res, err := execTypedDataCmd("hash " + mailTypedDataFile)
+	assert.Nil(t, err)
+	assert.Contains(t, res, "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2")
+	assert.Contains(t, res, "primaryType         | Mail\n")
+}
+
+func Test_TypedDataCmd_Verify(t *testing.T) {
+	res, err := execTypedDataCmd("verify " + mailTypedDataFile + " --signature " + mailSignature)
+	assert.Nil(t, err)
+	assert.Equal(t, "=> valid signature by 0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826\n", res)
+
+	_, err = execTypedDataCmd("verify " + mailTypedDataFile + " --signature " + mailSignature + " --address 0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")
+	assert.Nil(t, err)
+
+	_, err = execTypedDataCmd("verify " + mailTypedDataFile + " --signature " + mailSignature + " --address 0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB")
+	assert.NotNil(t, err)
+}
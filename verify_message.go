@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/0xsequence/ethkit/go-ethereum/accounts"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/0xsequence/ethkit/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagVerifyAddress   = "address"
+	flagVerifySignature = "signature"
+)
+
+func init() {
+	rootCmd.AddCommand(NewVerifyMessageCmd())
+}
+
+type verifyMessage struct {
+}
+
+// NewVerifyMessageCmd returns a new command to verify the EIP-191 personal_sign signature of a message.
+func NewVerifyMessageCmd() *cobra.Command {
+	c := &verifyMessage{}
+	cmd := &cobra.Command{
+		Use:   "verify-message [message]",
+		Short: "Verify the EIP-191 personal_sign signature of a message, a 0x-prefixed message being hex-decoded",
+		Example: `  ethkit verify-message --address 0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266 --signature 0x... "hello"
+  ethkit verify-message --address 0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266 --signature 0x... 0x68656c6c6f`,
+		Args: cobra.ExactArgs(1),
+		RunE: c.Run,
+	}
+
+	cmd.Flags().String(flagVerifyAddress, "", "The address of the expected signer (required)")
+	cmd.Flags().String(flagVerifySignature, "", "The 65 bytes 0x-prefixed signature, with a v of 27/28 or 0/1 (required)")
+
+	return cmd
+}
+
+func (c *verifyMessage) Run(cmd *cobra.Command, args []string) error {
+	fAddress, err := cmd.Flags().GetString(flagVerifyAddress)
+	if err != nil {
+		return err
+	}
+	fSignature, err := cmd.Flags().GetString(flagVerifySignature)
+	if err != nil {
+		return err
+	}
+
+	if !common.IsHexAddress(fAddress) {
+		return errors.New("error: please provide a valid --address (e.g. 0x213a286A1AF3Ac010d4F2D66A52DeAf762dF7742)")
+	}
+	signature, err := hexutil.Decode(fSignature)
+	if err != nil || len(signature) != crypto.SignatureLength {
+		return errors.New("error: please provide a valid --signature of 65 bytes, 0x-prefixed")
+	}
+
+	message, err := parseMessage(args[0])
+	if err != nil {
+		return err
+	}
+
+	signer, err := recoverMessageSigner(message, signature)
+	if err != nil {
+		return err
+	}
+
+	address := common.HexToAddress(fAddress)
+	res := &MessageVerification{Address: address, Signer: signer, Valid: signer == address}
+	if err := printResult(cmd, res); err != nil {
+		return err
+	}
+
+	if !res.Valid {
+		return fmt.Errorf("error: the message was not signed by %s", address.Hex())
+	}
+
+	return nil
+}
+
+// parseMessage returns the bytes of a message, which is hex-decoded when 0x-prefixed and taken as text otherwise.
+func parseMessage(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return []byte(s), nil
+	}
+
+	message, err := hexutil.Decode("0x" + s[2:])
+	if err != nil {
+		return nil, fmt.Errorf("error: invalid hex message %q: %w", s, err)
+	}
+	return message, nil
+}
+
+// recoverMessageSigner recovers the signer of the EIP-191 personal_sign signature of a message.
+func recoverMessageSigner(message, signature []byte) (common.Address, error) {
+	return recoverSigner(accounts.TextHash(message), signature)
+}
+
+// recoverSigner recovers the signer of the signature of a digest.
+func recoverSigner(digest, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("error: invalid signature length %d, expected %d", len(signature), crypto.SignatureLength)
+	}
+
+	// the recovery id is expected as 0/1 but signatures mostly come with a v of 27/28
+	sig := make([]byte, len(signature))
+	copy(sig, signature)
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	if sig[64] > 1 {
+		return common.Address{}, fmt.Errorf("error: invalid signature v %d, expected 27, 28, 0 or 1", signature[64])
+	}
+
+	pub, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("error: could not recover the signer: %w", err)
+	}
+
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// MessageVerification is the result of the verification of a message signature.
+type MessageVerification struct {
+	Address common.Address `json:"address"`
+	Signer  common.Address `json:"signer"`
+	Valid   bool           `json:"valid"`
+}
+
+// String overrides the standard behavior for MessageVerification "to-string".
+func (v *MessageVerification) String() string {
+	if v.Valid {
+		return fmt.Sprintf("=> valid signature by %s", v.Signer.Hex())
+	}
+	return fmt.Sprintf("=> invalid signature, recovered signer %s", v.Signer.Hex())
+}
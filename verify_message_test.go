@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/0xsequence/ethkit/ethwallet"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func execVerifyMessageCmd(args string) (string, error) {
+	cmd := NewVerifyMessageCmd()
+	actual := new(bytes.Buffer)
+	cmd.SetOut(actual)
+	cmd.SetErr(actual)
+	cmd.SetArgs(strings.Split(args, " "))
+	if err := cmd.Execute(); err != nil {
+		return "", err
+	}
+
+	return actual.String(), nil
+}
+
+func Test_ParseMessage(t *testing.T) {
+	m, err := parseMessage("hello")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("hello"), m)
+
+	m, err = parseMessage("0x68656c6c6f")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("hello"), m)
+
+	_, err = parseMessage("0xzz")
+	assert.NotNil(t, err)
+}
+
+func Test_VerifyMessageCmd(t *testing.T) {
+	wallet, err := ethwallet.NewWalletFromMnemonic(testMnemonic)
+	assert.Nil(t, err)
+	sig, err := wallet.SignMessage([]byte("hello"))
+	assert.Nil(t, err)
+	address := wallet.Address().Hex()
+
+	res, err := execVerifyMessageCmd("--address " + address + " --signature " + hexutil.Encode(sig) + " hello")
+	assert.Nil(t, err)
+	assert.Equal(t, "=> valid signature by "+address+"\n", res)
+
+	// the same message in hex and a v of 0/1
+	sig[64] -= 27
+	_, err = execVerifyMessageCmd("--address " + address + " --signature " + hexutil.Encode(sig) + " 0x68656c6c6f")
+	assert.Nil(t, err)
+
+	_, err = execVerifyMessageCmd("--address " + address + " --signature " + hexutil.Encode(sig) + " bye")
+	assert.NotNil(t, err)
+}
+
+func Test_VerifyMessageCmd_InvalidArgs(t *testing.T) {
+	for _, args := range []string{
+		"--signature 0x00 hello",
+		"--address 0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266 hello",
+		"--address 0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266 --signature 0x1234 hello",
+		"--address 0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266 --signature 0x" + strings.Repeat("11", 65) + " hello",
+	} {
+		_, err := execVerifyMessageCmd(args)
+		assert.NotNil(t, err, args)
+	}
+}
@@ -1,14 +1,14 @@
 package main
 
 import (
-	"bufio"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"io"
 	"os"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/0xsequence/ethkit/ethwallet"
 	"github.com/0xsequence/ethkit/go-ethereum/accounts/keystore"
@@ -17,238 +17,858 @@ import (
 	"golang.org/x/crypto/ssh/terminal"
 )
 
+const (
+	flagWalletKeyFile    = "keyfile"
+	flagWalletPath       = "path"
+	flagWalletMnemonic   = "mnemonic"
+	flagWalletPrivateKey = "private-key"
+	flagWalletKeystore   = "keystore"
+	flagWalletFormat     = "format"
+	flagWalletOut        = "out"
+
+	// deprecated flags of the wallet command, mapped onto the subcommands
+	flagWalletNew             = "new"
+	flagWalletImportMnemonic  = "import-mnemonic"
+	flagWalletPrintAccount    = "print-account"
+	flagWalletPrintMnemonic   = "print-mnemonic"
+	flagWalletPrintPrivateKey = "print-private-key"
+)
+
 func init() {
-	wallet := &wallet{}
+	rootCmd.AddCommand(NewWalletCmd())
+}
+
+type walletCmd struct {
+}
+
+// NewWalletCmd returns a new command to create and manage encrypted wallet key files.
+func NewWalletCmd() *cobra.Command {
+	c := &walletCmd{}
 	cmd := &cobra.Command{
 		Use:   "wallet",
 		Short: "EOA wallet",
-		Run:   wallet.Run,
-	}
-
-	cmd.Flags().String("keyfile", "", "wallet key file path")
-	cmd.Flags().Bool("new", false, "create a new wallet and save it to the keyfile")
-	cmd.Flags().Bool("print-account", true, "print wallet account address from keyfile")
-	cmd.Flags().Bool("print-mnemonic", false, "print wallet secret mnemonic from keyfile (danger!)")
-	cmd.Flags().Bool("print-private-key", false, "print wallet private key from keyfile (danger!)")
-	cmd.Flags().Bool("import-mnemonic", false, "import a secret mnemonic to a new keyfile")
-	cmd.Flags().String("path", "", fmt.Sprintf("set derivation path, default: %s", ethwallet.DefaultWalletOptions.DerivationPath))
-
-	rootCmd.AddCommand(cmd)
-}
-
-type wallet struct {
-	// flags
-	fKeyFile         string
-	fCreateNew       bool
-	fPrintAccount    bool
-	fPrintMnemonic   bool
-	fPrintPrivateKey bool
-	fImportMnemonic  bool
-	fPath            string
-
-	// wallet key file
-	keyFile walletKeyFile
-	wallet  *ethwallet.Wallet
-}
-
-func (c *wallet) Run(cmd *cobra.Command, args []string) {
-	c.fKeyFile, _ = cmd.Flags().GetString("keyfile")
-	c.fCreateNew, _ = cmd.Flags().GetBool("new")
-	c.fPrintAccount, _ = cmd.Flags().GetBool("print-account")
-	c.fPrintMnemonic, _ = cmd.Flags().GetBool("print-mnemonic")
-	c.fPrintPrivateKey, _ = cmd.Flags().GetBool("print-private-key")
-	c.fImportMnemonic, _ = cmd.Flags().GetBool("import-mnemonic")
-	c.fPath, _ = cmd.Flags().GetString("path")
-
-	if c.fKeyFile == "" {
-		fmt.Println("error: please pass --keyfile")
-		help(cmd)
-		return
-	}
-	if fileExists(c.fKeyFile) && (c.fCreateNew || c.fImportMnemonic) {
-		fmt.Println("error: keyfile already exists on this filename, for safety we do not overwrite existing keyfiles.")
-		help(cmd)
-		return
-	}
-	if !c.fCreateNew && !c.fImportMnemonic && !c.fPrintMnemonic && !c.fPrintPrivateKey && !c.fPrintAccount {
-		fmt.Println("error: not enough options provided to ethkit cli.")
-		help(cmd)
-		return
-	}
-
-	// Gen new wallet
-	if c.fCreateNew || c.fImportMnemonic {
-		if err := c.createNew(); err != nil {
-			log.Fatal(err)
-		}
-		return
+		Args:  cobra.NoArgs,
+		RunE:  c.Legacy,
+	}
+
+	cmd.PersistentFlags().String(flagWalletKeyFile, "", "wallet key file path")
+	cmd.PersistentFlags().String(flagWalletPath, "", fmt.Sprintf("set derivation path, default: %s", ethwallet.DefaultWalletOptions.DerivationPath))
+	addPasswordFlags(cmd.PersistentFlags())
+
+	cmd.Flags().Bool(flagWalletNew, false, "create a new wallet and save it to the keyfile")
+	cmd.Flags().Bool(flagWalletPrintAccount, true, "print wallet account address from keyfile")
+	cmd.Flags().Bool(flagWalletPrintMnemonic, false, "print wallet secret mnemonic from keyfile (danger!)")
+	cmd.Flags().Bool(flagWalletPrintPrivateKey, false, "print wallet private key from keyfile (danger!)")
+	cmd.Flags().Bool(flagWalletImportMnemonic, false, "import a secret mnemonic to a new keyfile")
+	cmd.Flags().MarkDeprecated(flagWalletNew, "use `ethkit wallet new` instead")
+	cmd.Flags().MarkDeprecated(flagWalletPrintAccount, "use `ethkit wallet address` instead")
+	cmd.Flags().MarkDeprecated(flagWalletPrintMnemonic, "use `ethkit wallet export --mnemonic` instead")
+	cmd.Flags().MarkDeprecated(flagWalletPrintPrivateKey, "use `ethkit wallet export --private-key` instead")
+	cmd.Flags().MarkDeprecated(flagWalletImportMnemonic, "use `ethkit wallet import` instead")
+
+	newCmd := &cobra.Command{
+		Use:   "new",
+		Short: "Create a new wallet and save it to the keyfile",
+		Args:  cobra.NoArgs,
+		RunE:  c.New,
+	}
+	newCmd.Flags().Int(flagMnemonicWords, defaultMnemonicWords, "The number of words of the mnemonic: 12, 15, 18, 21 or 24")
+	newCmd.Flags().Bool(flagWalletPassphrase, false, "Derive the accounts with a BIP-39 passphrase, which is asked for and not saved")
+	addKdfFlags(newCmd.Flags())
+
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import a secret mnemonic, a private key or a keystore V3 file to a new keyfile",
+		Example: `  ethkit wallet import --keyfile wallet.json
+  ethkit wallet import --keyfile wallet.json --private-key
+  ethkit wallet import --keyfile wallet.json --keystore UTC--2024-01-01T00-00-00.000000000Z--f39fd6e51aad88f6f4ce6ab8827279cfffb92266`,
+		Args: cobra.NoArgs,
+		RunE: c.Import,
+	}
+	importCmd.Flags().String(flagWalletKeystore, "", "Import the private key of a Web3 Secret Storage (keystore V3) file")
+	importCmd.Flags().Bool(flagWalletPrivateKey, false, "Import a private key instead of a mnemonic")
+	importCmd.Flags().Bool(flagWalletPassphrase, false, "Derive the accounts of the mnemonic with a BIP-39 passphrase, which is asked for and not saved")
+	addKdfFlags(importCmd.Flags())
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Decrypt the keyfile and show the details of the wallet",
+		Args:  cobra.NoArgs,
+		RunE:  c.Show,
+	}
+
+	addressCmd := &cobra.Command{
+		Use:   "address",
+		Short: "Print the wallet account address, the keyfile is only decrypted for another --path",
+		Args:  cobra.NoArgs,
+		RunE:  c.Address,
+	}
+
+	infoCmd := &cobra.Command{
+		Use:   "info",
+		Short: "Print the details of the keyfile stored in clear and audit its security settings, without a password",
+		Example: `  ethkit wallet info --keyfile wallet.json
+  ethkit wallet info --dir ./keys`,
+		Args: cobra.NoArgs,
+		RunE: c.Info,
+	}
+	infoCmd.Flags().String(flagWalletDir, "", "Audit every keyfile of a directory")
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Print the secret mnemonic or private key of the wallet (danger!), or export a keystore V3 file",
+		Example: `  ethkit wallet export --keyfile wallet.json --private-key
+  ethkit wallet export --keyfile wallet.json --mnemonic --clipboard-free --to-file mnemonic.txt
+  ethkit wallet export --keyfile wallet.json --format keystore-v3 --path "m/44'/60'/0'/0/1" --out keystore.json`,
+		Args: cobra.NoArgs,
+		RunE: c.Export,
+	}
+	exportCmd.Flags().Bool(flagWalletMnemonic, false, "Print the secret mnemonic")
+	exportCmd.Flags().Bool(flagWalletPrivateKey, false, "Print the private key of the account at --path")
+	exportCmd.Flags().String(flagWalletFormat, "", "Export the account at --path to an encrypted file of the format: keystore-v3")
+	exportCmd.Flags().String(flagWalletOut, "", "The file to export to, default: stdout")
+	exportCmd.Flags().String(flagWalletToFile, "", "Write the mnemonic or private key to a new file readable only by you instead of printing it")
+	exportCmd.Flags().Bool(flagWalletClipboardFree, false, "Ask to type the account address before the mnemonic or private key is revealed")
+	addKdfFlags(exportCmd.Flags())
+
+	deriveCmd := &cobra.Command{
+		Use:   "derive",
+		Short: "List the accounts derived from the wallet mnemonic, or the account at --path",
+		Example: `  ethkit wallet derive --keyfile wallet.json --count 20 --start 0 --path-template "m/44'/60'/0'/0/{i}"
+  ethkit wallet derive --keyfile wallet.json --preset ledger-live --balance --nonce`,
+		Args: cobra.NoArgs,
+		RunE: c.Derive,
+	}
+	deriveCmd.Flags().Int(flagWalletCount, 10, "The number of accounts to derive")
+	deriveCmd.Flags().Int(flagWalletStart, 0, "The index of the first account to derive")
+	deriveCmd.Flags().String(flagWalletPathTemplate, "", "The derivation path template, where {i} is replaced by the account index")
+	deriveCmd.Flags().String(flagWalletPreset, derivePresetDefault, "The derivation path preset: "+strings.Join(derivePresetNames(), ", "))
+	deriveCmd.Flags().Bool(flagWalletBalance, false, "Fetch the balance of the accounts")
+	deriveCmd.Flags().Bool(flagWalletNonce, false, "Fetch the nonce of the accounts")
+	addRpcFlags(deriveCmd)
+
+	vanityCmd := &cobra.Command{
+		Use:   "vanity",
+		Short: "Create a new wallet whose address matches a prefix and/or a suffix, and save it to the keyfile",
+		Example: `  ethkit wallet vanity --keyfile wallet.json --prefix 0xdead --suffix beef
+  ethkit wallet vanity --keyfile wallet.json --prefix 0xC0FFEE --case-sensitive --private-key --workers 8`,
+		Args: cobra.NoArgs,
+		RunE: c.Vanity,
+	}
+	vanityCmd.Flags().String(flagWalletPrefix, "", "The hex prefix of the address")
+	vanityCmd.Flags().String(flagWalletSuffix, "", "The hex suffix of the address")
+	vanityCmd.Flags().Bool(flagWalletCaseSensitive, false, "Match the case of the EIP-55 checksummed address")
+	vanityCmd.Flags().Int(flagWalletWorkers, 0, "The number of addresses generated in parallel, default: the number of CPUs")
+	vanityCmd.Flags().Bool(flagWalletPrivateKey, false, "Generate private keys instead of mnemonics, which is much faster")
+	vanityCmd.Flags().Int(flagMnemonicWords, defaultMnemonicWords, "The number of words of the mnemonics: 12, 15, 18, 21 or 24")
+	addKdfFlags(vanityCmd.Flags())
+
+	signMessageCmd := &cobra.Command{
+		Use:   "sign-message [message]",
+		Short: "Sign a message with EIP-191 personal_sign, a 0x-prefixed message being hex-decoded",
+		Example: `  ethkit wallet sign-message --keyfile wallet.json "hello"
+  ethkit wallet sign-message --keyfile wallet.json --v-format 0 0x68656c6c6f`,
+		Args: cobra.ExactArgs(1),
+		RunE: c.SignMessage,
+	}
+	signMessageCmd.Flags().String(flagWalletVFormat, vFormatEthereum, "The format of the signature v: 27 for 27/28, or 0 for 0/1")
+
+	signTypedDataCmd := &cobra.Command{
+		Use:     "sign-typed-data [file]",
+		Short:   "Sign the EIP-712 typed data of an eth_signTypedData_v4 JSON file, - for stdin",
+		Example: `  ethkit wallet sign-typed-data --keyfile wallet.json permit.json`,
+		Args:    cobra.ExactArgs(1),
+		RunE:    c.SignTypedData,
+	}
+	signTypedDataCmd.Flags().String(flagWalletVFormat, vFormatEthereum, "The format of the signature v: 27 for 27/28, or 0 for 0/1")
+
+	changePasswordCmd := &cobra.Command{
+		Use:   "change-password",
+		Short: "Re-encrypt the keyfile with a new password, keeping a backup of the previous keyfile",
+		Example: `  ethkit wallet change-password --keyfile wallet.json
+  ethkit wallet change-password --keyfile wallet.json --scrypt-n 1048576 --scrypt-p 1`,
+		Args: cobra.NoArgs,
+		RunE: c.ChangePassword,
 	}
+	addKdfFlags(changePasswordCmd.Flags())
 
-	// Load wallet from the key file
-	data, err := os.ReadFile(c.fKeyFile)
+	cmd.AddCommand(newCmd, importCmd, showCmd, addressCmd, infoCmd, exportCmd, deriveCmd, vanityCmd, signMessageCmd, signTypedDataCmd, changePasswordCmd)
+
+	return cmd
+}
+
+// Legacy maps the deprecated flags of the wallet command onto the matching subcommand.
+func (c *walletCmd) Legacy(cmd *cobra.Command, args []string) error {
+	if cmd.Flags().NFlag() == 0 {
+		return cmd.Help()
+	}
+
+	fNew, err := cmd.Flags().GetBool(flagWalletNew)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	keyFile := walletKeyFile{}
-	err = json.Unmarshal(data, &keyFile)
+	fImportMnemonic, err := cmd.Flags().GetBool(flagWalletImportMnemonic)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	c.keyFile = keyFile
-
-	derivationPath := c.fPath
-	if derivationPath == "" {
-		derivationPath = c.keyFile.Path
+	fPrintMnemonic, err := cmd.Flags().GetBool(flagWalletPrintMnemonic)
+	if err != nil {
+		return err
+	}
+	fPrintPrivateKey, err := cmd.Flags().GetBool(flagWalletPrintPrivateKey)
+	if err != nil {
+		return err
+	}
+	fPrintAccount, err := cmd.Flags().GetBool(flagWalletPrintAccount)
+	if err != nil {
+		return err
 	}
 
-	pw, err := readSecretInput("Password: ")
+	legacyArgs, err := legacyWalletArgs(fNew, fImportMnemonic, fPrintMnemonic, fPrintPrivateKey, fPrintAccount)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	sub, subArgs, err := cmd.Find(legacyArgs)
+	if err != nil {
+		return err
 	}
+	// the persistent --keyfile and --path flags are shared with the subcommand
+	if err := sub.ParseFlags(subArgs); err != nil {
+		return err
+	}
+
+	return sub.RunE(sub, sub.Flags().Args())
+}
 
-	cipherText, err := keystore.DecryptDataV3(c.keyFile.Crypto, string(pw))
+// legacyWalletArgs returns the subcommand arguments matching the deprecated flags of the wallet command.
+func legacyWalletArgs(fNew, fImportMnemonic, fPrintMnemonic, fPrintPrivateKey, fPrintAccount bool) ([]string, error) {
+	switch {
+	case fNew:
+		return []string{"new"}, nil
+	case fImportMnemonic:
+		return []string{"import"}, nil
+	case fPrintMnemonic:
+		return []string{"export", "--" + flagWalletMnemonic}, nil
+	case fPrintPrivateKey:
+		return []string{"export", "--" + flagWalletPrivateKey}, nil
+	case fPrintAccount:
+		return []string{"address"}, nil
+	default:
+		return nil, errors.New("error: not enough options provided to ethkit cli.")
+	}
+}
+
+func (c *walletCmd) New(cmd *cobra.Command, args []string) error {
+	fWords, err := cmd.Flags().GetInt(flagMnemonicWords)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	if _, err := mnemonicEntropyBits(fWords); err != nil {
+		return err
 	}
 
-	wallet, err := ethwallet.NewWalletFromMnemonic(string(cipherText), derivationPath)
+	return c.create(cmd, func(passwords *passwordSource, derivationPath string) (*ethwallet.Wallet, error) {
+		return newRandomWallet(fWords, derivationPath)
+	})
+}
+
+func (c *walletCmd) Import(cmd *cobra.Command, args []string) error {
+	fKeystore, err := cmd.Flags().GetString(flagWalletKeystore)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	fPrivateKey, err := cmd.Flags().GetBool(flagWalletPrivateKey)
+	if err != nil {
+		return err
 	}
-	c.wallet = wallet
 
-	// Print mnemonic
-	if c.fPrintMnemonic {
-		if err := c.printMnemonic(); err != nil {
-			log.Fatal(err)
-		}
-		return
+	if fKeystore != "" && fPrivateKey {
+		return fmt.Errorf("error: please pass either --%s or --%s", flagWalletKeystore, flagWalletPrivateKey)
+	}
+	if fKeystore != "" && !fileExists(fKeystore) {
+		return fmt.Errorf("error: keystore %s does not exist", fKeystore)
+	}
+	if (fKeystore != "" || fPrivateKey) && cmd.Flags().Changed(flagWalletPath) {
+		return fmt.Errorf("error: --%s cannot be used to import a single private key", flagWalletPath)
+	}
+	if (fKeystore != "" || fPrivateKey) && cmd.Flags().Changed(flagWalletPassphrase) {
+		return fmt.Errorf("error: --%s only applies to mnemonics", flagWalletPassphrase)
 	}
 
-	// Print private key
-	if c.fPrintPrivateKey {
-		if err := c.printPrivateKey(); err != nil {
-			log.Fatal(err)
-		}
-		return
+	switch {
+	case fKeystore != "":
+		return c.create(cmd, func(passwords *passwordSource, derivationPath string) (*ethwallet.Wallet, error) {
+			return readKeystoreV3(passwords, fKeystore)
+		})
+
+	case fPrivateKey:
+		return c.create(cmd, func(passwords *passwordSource, derivationPath string) (*ethwallet.Wallet, error) {
+			input, err := readMaskedInput("Enter your private key to import: ")
+			if err != nil {
+				return nil, err
+			}
+			defer zeroBytes(input)
+			wallet, err := ethwallet.NewWalletFromPrivateKey(strings.TrimPrefix(strings.TrimSpace(string(input)), "0x"))
+			if err != nil {
+				return nil, fmt.Errorf("error: invalid private key: %w", err)
+			}
+			return wallet, nil
+		})
+
+	default:
+		return c.create(cmd, func(passwords *passwordSource, derivationPath string) (*ethwallet.Wallet, error) {
+			mnemonic, err := readMnemonic("Enter your mnemonic to import: ")
+			if err != nil {
+				return nil, err
+			}
+			return getWallet(mnemonic, derivationPath)
+		})
 	}
+}
 
-	// Print acconut address
-	if c.fPrintAccount {
-		if err := c.printAccount(); err != nil {
-			log.Fatal(err)
-		}
-		return
+func (c *walletCmd) Show(cmd *cobra.Command, args []string) error {
+	fKeyFile, err := walletKeyFileFlag(cmd, true)
+	if err != nil {
+		return err
 	}
+
+	keyFile, wallet, err := openWalletKeyFile(cmd, fKeyFile, "")
+	if err != nil {
+		return err
+	}
+
+	return printResult(cmd, &WalletDetails{
+		KeyFile: fKeyFile,
+		Kind:    keyFile.kind(),
+		Address: wallet.Address(),
+		Path:    keyFile.Path,
+		Client:  keyFile.Client,
+	})
 }
 
-func (c *wallet) printMnemonic() error {
-	fmt.Println("")
-	fmt.Println("=> Your Ethereum private mnemonic is:")
-	fmt.Println("=>", c.wallet.HDNode().Mnemonic())
-	fmt.Println("")
-	return nil
+func (c *walletCmd) Address(cmd *cobra.Command, args []string) error {
+	fKeyFile, err := walletKeyFileFlag(cmd, true)
+	if err != nil {
+		return err
+	}
+	fPath, err := cmd.Flags().GetString(flagWalletPath)
+	if err != nil {
+		return err
+	}
+
+	keyFile, err := readWalletKeyFile(fKeyFile)
+	if err != nil {
+		return err
+	}
+
+	// the address of the stored derivation path is saved in clear in the key file
+	if fPath == "" || fPath == keyFile.Path {
+		return printResult(cmd, &WalletAccount{Address: keyFile.Address, Path: keyFile.Path})
+	}
+
+	_, wallet, err := openWalletKeyFile(cmd, fKeyFile, fPath)
+	if err != nil {
+		return err
+	}
+
+	return printResult(cmd, &WalletAccount{Address: wallet.Address(), Path: keyFile.accountPath(fPath)})
 }
 
-func (c *wallet) printPrivateKey() error {
-	fmt.Println("")
-	fmt.Println("=> Your Ethereum private key is:")
-	fmt.Println("=>", c.wallet.PrivateKeyHex())
-	fmt.Println("")
-	return nil
+func (c *walletCmd) Export(cmd *cobra.Command, args []string) error {
+	fKeyFile, err := walletKeyFileFlag(cmd, true)
+	if err != nil {
+		return err
+	}
+	fPath, err := cmd.Flags().GetString(flagWalletPath)
+	if err != nil {
+		return err
+	}
+	fMnemonic, err := cmd.Flags().GetBool(flagWalletMnemonic)
+	if err != nil {
+		return err
+	}
+	fPrivateKey, err := cmd.Flags().GetBool(flagWalletPrivateKey)
+	if err != nil {
+		return err
+	}
+	fFormat, err := cmd.Flags().GetString(flagWalletFormat)
+	if err != nil {
+		return err
+	}
+	fOut, err := cmd.Flags().GetString(flagWalletOut)
+	if err != nil {
+		return err
+	}
+
+	if fFormat != "" {
+		if fFormat != walletFormatKeystoreV3 {
+			return fmt.Errorf("error: unknown --%s %q, supported: %s", flagWalletFormat, fFormat, walletFormatKeystoreV3)
+		}
+		if fMnemonic || fPrivateKey {
+			return fmt.Errorf("error: --%s cannot be used with --%s or --%s", flagWalletFormat, flagWalletMnemonic, flagWalletPrivateKey)
+		}
+		for _, name := range []string{flagWalletToFile, flagWalletClipboardFree} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("error: --%s only applies to --%s and --%s, use --%s", name, flagWalletMnemonic, flagWalletPrivateKey, flagWalletOut)
+			}
+		}
+		if fOut != "" && fileExists(fOut) {
+			return fmt.Errorf("error: %s already exists, for safety we do not overwrite existing files", fOut)
+		}
+		return c.exportKeystoreV3(cmd, fKeyFile, fPath, fOut)
+	}
+	if fOut != "" {
+		return fmt.Errorf("error: --%s can only be used with --%s %s", flagWalletOut, flagWalletFormat, walletFormatKeystoreV3)
+	}
+	if fMnemonic == fPrivateKey {
+		return fmt.Errorf("error: please pass either --%s or --%s", flagWalletMnemonic, flagWalletPrivateKey)
+	}
+	dest, err := secretDestinationFor(cmd)
+	if err != nil {
+		return err
+	}
+
+	if fMnemonic {
+		return c.exportMnemonic(cmd, fKeyFile, dest)
+	}
+
+	_, wallet, err := openWalletKeyFile(cmd, fKeyFile, fPath)
+	if err != nil {
+		return err
+	}
+	privateKey := []byte(wallet.PrivateKeyHex())
+	defer zeroBytes(privateKey)
+	return dest.reveal(cmd, wallet.Address(), "private key", privateKey, &WalletPrivateKey{PrivateKey: string(privateKey)})
 }
 
-func (c *wallet) printAccount() error {
-	fmt.Println("")
-	fmt.Println("")
-	fmt.Println("=> Your Ethereum wallet address is:", c.wallet.Address().String())
-	fmt.Println("")
-	return nil
+// exportMnemonic prints the mnemonic of a key file, without its BIP-39 passphrase which is not saved.
+func (c *walletCmd) exportMnemonic(cmd *cobra.Command, keyFilePath string, dest *secretDestination) error {
+	keyFile, err := readWalletKeyFile(keyFilePath)
+	if err != nil {
+		return err
+	}
+	if keyFile.kind() != walletKindMnemonic {
+		return errors.New("error: the keyfile holds a private key, it has no mnemonic")
+	}
+
+	passwords, err := passwordSourceFor(cmd)
+	if err != nil {
+		return err
+	}
+	pw, err := passwords.read("Password: ")
+	if err != nil {
+		return err
+	}
+	defer zeroBytes(pw)
+	mnemonic, err := keyFile.decryptSecret(pw)
+	if err != nil {
+		return err
+	}
+	defer zeroBytes(mnemonic)
+
+	return dest.reveal(cmd, keyFile.Address, "mnemonic", mnemonic, &WalletMnemonic{Mnemonic: string(mnemonic)})
 }
 
-func (c *wallet) createNew() error {
-	var err error
-	var importMnemonic string
+func (c *walletCmd) ChangePassword(cmd *cobra.Command, args []string) error {
+	fKeyFile, err := walletKeyFileFlag(cmd, true)
+	if err != nil {
+		return err
+	}
 
-	if c.fImportMnemonic {
-		var mnemonic []byte
-		// TODO: use crypto/terminal and print *'s on each keypress of input
-		mnemonic, err = readPlainInput("Enter your mnemonic to import: ")
-		if err != nil {
-			return err
-		}
-		importMnemonic = strings.TrimSpace(string(mnemonic))
+	passwords, err := passwordSourceFor(cmd)
+	if err != nil {
+		return err
+	}
+	kdf, err := kdfParamsFor(cmd)
+	if err != nil {
+		return err
 	}
 
-	derivationPath := c.fPath
-	if derivationPath == "" {
-		derivationPath = ethwallet.DefaultWalletOptions.DerivationPath
+	keyFile, err := readWalletKeyFile(fKeyFile)
+	if err != nil {
+		return err
 	}
 
-	c.wallet, err = getWallet(importMnemonic, derivationPath)
+	currentPw, err := passwords.read("Current Password: ")
 	if err != nil {
 		return err
 	}
+	defer zeroBytes(currentPw)
 
-	pw, err := readSecretInput("Password: ")
+	// the secret is re-encrypted as is, the key file keeping its account and the passphrase not being needed
+	secret, err := keyFile.decryptSecret(currentPw)
 	if err != nil {
 		return err
 	}
-	if len(pw) < 8 {
-		return errors.New("password must be at least 8 characters")
+	defer zeroBytes(secret)
+
+	pw, err := readNewPassword(passwords, "New Password: ", "Confirm New Password: ")
+	if err != nil {
+		return err
 	}
+	defer zeroBytes(pw)
 
-	fmt.Println("")
-	confirmPw, err := readSecretInput("Confirm Password: ")
+	newKeyFile := *keyFile
+	if newKeyFile.Crypto, err = encryptDataV3(secret, pw, kdf); err != nil {
+		return err
+	}
+	newKeyFile.Client = walletClient()
+
+	// the previous key file is kept until the new one is safely written in its place, next to the backups of earlier
+	// changes, which may be the only copies encrypted with a password that still matters
+	previous, err := os.ReadFile(fKeyFile)
 	if err != nil {
 		return err
 	}
-	if string(pw) != string(confirmPw) {
-		return errors.New("passwords do not match")
+	backup := keyFileBackupPath(fKeyFile, time.Now())
+	if err := writeNewFileAtomic(backup, previous, 0600); err != nil {
+		return fmt.Errorf("error: could not back up the keyfile to %s: %w", backup, err)
 	}
 
-	cryptoJSON, err := keystore.EncryptDataV3([]byte(c.wallet.HDNode().Mnemonic()), pw, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err := writeWalletKeyFile(fKeyFile, &newKeyFile); err != nil {
+		return err
+	}
+
+	return printResult(cmd, &WalletPasswordChanged{KeyFile: fKeyFile, Backup: backup, Address: keyFile.Address})
+}
+
+// keyFileBackupPath returns a path not taken yet for a backup of a key file, named after the unix time of the backup,
+// e.g. wallet.json.1700000000.bak.
+func keyFileBackupPath(keyFile string, now time.Time) string {
+	backup := fmt.Sprintf("%s.%d.bak", keyFile, now.Unix())
+	for i := 1; fileExists(backup); i++ {
+		backup = fmt.Sprintf("%s.%d-%d.bak", keyFile, now.Unix(), i)
+	}
+	return backup
+}
+
+// create creates a new key file for the wallet of a random or an imported secret.
+func (c *walletCmd) create(cmd *cobra.Command, newWallet func(passwords *passwordSource, derivationPath string) (*ethwallet.Wallet, error)) error {
+	fKeyFile, err := walletKeyFileFlag(cmd, false)
+	if err != nil {
+		return err
+	}
+	fPath, err := cmd.Flags().GetString(flagWalletPath)
+	if err != nil {
+		return err
+	}
+	passwords, err := passwordSourceFor(cmd)
+	if err != nil {
+		return err
+	}
+	kdf, err := kdfParamsFor(cmd)
 	if err != nil {
 		return err
 	}
 
-	keyFile := walletKeyFile{
-		Address: c.wallet.Address(),
-		Path:    c.wallet.HDNode().DerivationPath().String(),
-		Crypto:  cryptoJSON,
-		Client:  fmt.Sprintf("ethkit/%s - github.com/0xsequence/ethkit", VERSION),
+	fPassphrase := false
+	if f := cmd.Flag(flagWalletPassphrase); f != nil && f.Value.String() == "true" {
+		fPassphrase = true
 	}
 
-	data, err := json.MarshalIndent(keyFile, "", "  ")
+	if fPath == "" {
+		fPath = ethwallet.DefaultWalletOptions.DerivationPath
+	}
+
+	wallet, err := newWallet(passwords, fPath)
 	if err != nil {
 		return err
 	}
-	data = append(data, []byte("\n")...)
 
-	if err := os.WriteFile(c.fKeyFile, data, 0600); err != nil {
+	// the password is read before the passphrase, in the order every command unlocking the key file reads them
+	pw, err := readNewPassword(passwords, "Password: ", "Confirm Password: ")
+	if err != nil {
 		return err
 	}
+	defer zeroBytes(pw)
 
-	fmt.Println("")
-	fmt.Println("")
-	fmt.Println("=> success! ethkit has generated a new Ethereum wallet for you and saved")
-	fmt.Println("=> it in an encrypted+password protected file at:")
-	fmt.Println("=> ---")
-	fmt.Println("=>", c.fKeyFile)
-	fmt.Println("")
-	fmt.Printf("=> to confirm, please run: ./ethkit wallet --keyfile=%s --print-account\n", c.fKeyFile)
-	fmt.Println("")
-	fmt.Println("=> Your new Ethereum wallet address is:", c.wallet.Address().String())
-	fmt.Println("")
+	// with a passphrase, the account is derived from the mnemonic and the passphrase, only the mnemonic being saved
+	var account *ethwallet.Wallet
+	if fPassphrase {
+		passphrase, err := readNewPassphrase(passwords)
+		if err != nil {
+			return err
+		}
+		defer zeroBytes(passphrase)
+		w, err := newPassphraseWallet(wallet.HDNode().Mnemonic(), passphrase)
+		if err != nil {
+			return err
+		}
+		if account, err = w.account(fPath); err != nil {
+			return err
+		}
+	}
 
-	return nil
+	keyFile, err := newWalletKeyFile(wallet, pw, kdf)
+	if err != nil {
+		return err
+	}
+	if account != nil {
+		keyFile.Address, keyFile.Passphrase = account.Address(), true
+	}
+
+	if err := writeWalletKeyFile(fKeyFile, keyFile); err != nil {
+		return err
+	}
+
+	return printResult(cmd, &WalletCreated{
+		KeyFile: fKeyFile,
+		Address: keyFile.Address,
+		Path:    keyFile.Path,
+	})
+}
+
+// walletKeyFileFlag returns the --keyfile of a wallet command, which must exist, or not exist yet when creating a wallet.
+func walletKeyFileFlag(cmd *cobra.Command, exists bool) (string, error) {
+	fKeyFile, err := cmd.Flags().GetString(flagWalletKeyFile)
+	if err != nil {
+		return "", err
+	}
+
+	if fKeyFile == "" {
+		return "", errors.New("error: please pass --keyfile")
+	}
+	if exists && !fileExists(fKeyFile) {
+		return "", fmt.Errorf("error: keyfile %s does not exist", fKeyFile)
+	}
+	if !exists && fileExists(fKeyFile) {
+		return "", errors.New("error: keyfile already exists on this filename, for safety we do not overwrite existing keyfiles")
+	}
+
+	return fKeyFile, nil
+}
+
+// WalletAccount is the account of a wallet key file.
+type WalletAccount struct {
+	Address common.Address `json:"address"`
+	Path    string         `json:"path"`
+}
+
+// String overrides the standard behavior for WalletAccount "to-string".
+func (a *WalletAccount) String() string {
+	return fmt.Sprintf("=> Your Ethereum wallet address is: %s", a.Address.String())
+}
+
+// WalletDetails are the details of a decrypted wallet key file.
+type WalletDetails struct {
+	KeyFile string         `json:"keyfile"`
+	Kind    string         `json:"kind"`
+	Address common.Address `json:"address"`
+	Path    string         `json:"path"`
+	Client  string         `json:"client"`
+}
+
+// String overrides the standard behavior for WalletDetails "to-string".
+func (d *WalletDetails) String() string {
+	var p Printable
+	if err := p.FromStruct(d); err != nil {
+		panic(err)
+	}
+	// the address is printed with its EIP-55 checksum rather than as the lowercase hex of its JSON encoding
+	p.Set("address", d.Address.Hex())
+	return p.Columnize(*NewPrintableFormat(20, 0, 0, byte(' ')))
+}
+
+// WalletMnemonic is the secret mnemonic of a wallet key file.
+type WalletMnemonic struct {
+	Mnemonic string `json:"mnemonic"`
+}
+
+// String overrides the standard behavior for WalletMnemonic "to-string".
+func (m *WalletMnemonic) String() string {
+	return fmt.Sprintf("=> Your Ethereum private mnemonic is:\n=> %s", m.Mnemonic)
+}
+
+// WalletPrivateKey is the private key of a wallet key file.
+type WalletPrivateKey struct {
+	PrivateKey string `json:"privateKey"`
+}
+
+// String overrides the standard behavior for WalletPrivateKey "to-string".
+func (k *WalletPrivateKey) String() string {
+	return fmt.Sprintf("=> Your Ethereum private key is:\n=> %s", k.PrivateKey)
+}
+
+// WalletPasswordChanged is the result of the change of the password of a wallet key file.
+type WalletPasswordChanged struct {
+	KeyFile string         `json:"keyfile"`
+	Backup  string         `json:"backup"`
+	Address common.Address `json:"address"`
+}
+
+// String overrides the standard behavior for WalletPasswordChanged "to-string".
+func (w *WalletPasswordChanged) String() string {
+	return fmt.Sprintf("=> success! the keyfile %s of %s has been encrypted with the new password, the previous keyfile is kept in %s", w.KeyFile, w.Address.String(), w.Backup)
+}
+
+// WalletCreated is the result of the creation of a new wallet key file.
+type WalletCreated struct {
+	KeyFile string         `json:"keyfile"`
+	Address common.Address `json:"address"`
+	Path    string         `json:"path"`
+}
+
+// String overrides the standard behavior for WalletCreated "to-string".
+func (w *WalletCreated) String() string {
+	return fmt.Sprintf(`=> success! ethkit has generated a new Ethereum wallet for you and saved
+=> it in an encrypted+password protected file at:
+=> ---
+=> %s
+
+=> to confirm, please run: ethkit wallet address --keyfile=%s
+
+=> Your new Ethereum wallet address is: %s`, w.KeyFile, w.KeyFile, w.Address.String())
 }
 
 type walletKeyFile struct {
-	Address common.Address      `json:"address"`
-	Path    string              `json:"path"`
-	Crypto  keystore.CryptoJSON `json:"crypto"`
-	Client  string              `json:"client"`
+	Address common.Address `json:"address"`
+	Path    string         `json:"path"`
+	Kind    string         `json:"kind,omitempty"`
+	// Passphrase is set for the wallets of a mnemonic and a BIP-39 passphrase, which is not saved
+	Passphrase bool                `json:"passphrase,omitempty"`
+	Crypto     keystore.CryptoJSON `json:"crypto"`
+	Client     string              `json:"client"`
+}
+
+// readWalletKeyFile reads and parses a wallet key file.
+func readWalletKeyFile(path string) (*walletKeyFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keyFile := &walletKeyFile{}
+	if err := json.Unmarshal(data, keyFile); err != nil {
+		return nil, err
+	}
+	return keyFile, nil
+}
+
+// kind returns the kind of secret held by the key file, the key files without kind holding a mnemonic.
+func (k *walletKeyFile) kind() string {
+	if k.Kind == "" {
+		return walletKindMnemonic
+	}
+	return k.Kind
+}
+
+// decrypt decrypts the secret of the key file and returns the wallet for the derivation path,
+// which defaults to the path stored in the key file. The wallet of a private key has no derivation path.
+func (k *walletKeyFile) decrypt(password, passphrase []byte, derivationPath string) (*ethwallet.Wallet, error) {
+	switch k.kind() {
+	case walletKindMnemonic:
+	case walletKindPrivateKey:
+		if derivationPath != "" && derivationPath != k.Path {
+			return nil, errors.New("error: the keyfile holds a private key, it cannot derive other paths")
+		}
+	default:
+		return nil, fmt.Errorf("error: unknown keyfile kind %q", k.Kind)
+	}
+
+	if derivationPath == "" {
+		derivationPath = k.Path
+	}
+
+	secret, err := k.decryptSecret(password)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(secret)
+
+	// the string copies of the secret taken by ethwallet can not be zeroed, see zeroBytes
+	if k.kind() == walletKindPrivateKey {
+		return ethwallet.NewWalletFromPrivateKey(string(secret))
+	}
+	if k.Passphrase {
+		w, err := k.openPassphraseWallet(string(secret), passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return w.account(derivationPath)
+	}
+	return ethwallet.NewWalletFromMnemonic(string(secret), derivationPath)
+}
+
+// decryptSecret returns the mnemonic or the private key hex encrypted in a key file.
+func (k *walletKeyFile) decryptSecret(password []byte) ([]byte, error) {
+	return keystore.DecryptDataV3(k.Crypto, string(password))
+}
+
+// accountPath returns the derivation path of the account of a key file at a derivation path, the stored path by
+// default, which is empty for a private key.
+func (k *walletKeyFile) accountPath(derivationPath string) string {
+	if k.kind() == walletKindPrivateKey || derivationPath == "" {
+		return k.Path
+	}
+	path, err := ethwallet.ParseDerivationPath(derivationPath)
+	if err != nil {
+		return derivationPath
+	}
+	return path.String()
+}
+
+// openWalletKeyFile reads a key file and decrypts it with the password of the password source of the command.
+func openWalletKeyFile(cmd *cobra.Command, path, derivationPath string) (*walletKeyFile, *ethwallet.Wallet, error) {
+	passwords, err := passwordSourceFor(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return decryptWalletKeyFile(passwords, path, derivationPath)
+}
+
+// decryptWalletKeyFile reads a key file and decrypts it with the next password of a password source.
+func decryptWalletKeyFile(passwords *passwordSource, path, derivationPath string) (*walletKeyFile, *ethwallet.Wallet, error) {
+	keyFile, err := readWalletKeyFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wallet, err := keyFile.unlock(passwords, derivationPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return keyFile, wallet, nil
+}
+
+// newWalletKeyFile encrypts the mnemonic of a wallet with a password, or its private key for the wallets
+// imported without mnemonic.
+func newWalletKeyFile(wallet *ethwallet.Wallet, password []byte, params *kdfParams) (*walletKeyFile, error) {
+	kind, secret := walletKindMnemonic, wallet.HDNode().Mnemonic()
+	if secret == "" {
+		kind, secret = walletKindPrivateKey, strings.TrimPrefix(wallet.PrivateKeyHex(), "0x")
+	}
+
+	data := []byte(secret)
+	defer zeroBytes(data)
+	cryptoJSON, err := encryptDataV3(data, password, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &walletKeyFile{
+		Address: wallet.Address(),
+		Path:    walletPath(wallet),
+		Kind:    kind,
+		Crypto:  cryptoJSON,
+		Client:  walletClient(),
+	}, nil
+}
+
+// walletClient returns the client recorded in the key files written by this version.
+func walletClient() string {
+	return fmt.Sprintf("ethkit/%s - github.com/0xsequence/ethkit", VERSION)
+}
+
+// writeWalletKeyFile atomically writes a key file readable by the user only.
+func writeWalletKeyFile(path string, keyFile *walletKeyFile) error {
+	data, err := json.MarshalIndent(keyFile, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, []byte("\n")...)
+
+	return writeFileAtomic(path, data, 0600)
 }
 
 func fileExists(filename string) bool {
@@ -259,22 +879,71 @@ func fileExists(filename string) bool {
 	return !info.IsDir()
 }
 
+// readSecretInput prompts on stderr, keeping stdout for the command results, and reads a secret without echo.
 func readSecretInput(prompt string) ([]byte, error) {
-	fmt.Print(prompt)
+	fmt.Fprint(os.Stderr, prompt)
 	password, err := terminal.ReadPassword(int(syscall.Stdin))
+	fmt.Fprintln(os.Stderr)
 	if err != nil {
 		return nil, err
 	}
 	return password, nil
 }
 
+// walletPath returns the derivation path of a wallet, which is empty for a wallet without mnemonic.
+func walletPath(wallet *ethwallet.Wallet) string {
+	if wallet.HDNode().Mnemonic() == "" {
+		return ""
+	}
+	return wallet.HDNode().DerivationPath().String()
+}
+
+// readNewPassword reads a new password, along with its confirmation when it is prompted on the terminal.
+func readNewPassword(passwords *passwordSource, prompt, confirmPrompt string) ([]byte, error) {
+	pw, err := passwords.read(prompt)
+	if err != nil {
+		return nil, err
+	}
+	if len(pw) < 8 {
+		return nil, errors.New("password must be at least 8 characters")
+	}
+	if !passwords.interactive() {
+		return pw, nil
+	}
+
+	confirmPw, err := passwords.read(confirmPrompt)
+	if err != nil {
+		return nil, err
+	}
+	if string(pw) != string(confirmPw) {
+		return nil, errors.New("passwords do not match")
+	}
+
+	return pw, nil
+}
+
+// readPlainInput prompts on stderr, keeping stdout for the command results, and reads a line.
 func readPlainInput(prompt string) ([]byte, error) {
-	fmt.Print(prompt)
-	reader := bufio.NewReader(os.Stdin)
-	text, _ := reader.ReadString('\n')
+	fmt.Fprint(os.Stderr, prompt)
+	text, err := stdinReader.ReadString('\n')
+	if err != nil && (err != io.EOF || text == "") {
+		return nil, fmt.Errorf("error: could not read stdin: %w", err)
+	}
 	return []byte(text), nil
 }
 
+// newRandomWallet returns a new wallet of a random mnemonic of a number of words.
+func newRandomWallet(words int, derivationPath string) (*ethwallet.Wallet, error) {
+	bits, err := mnemonicEntropyBits(words)
+	if err != nil {
+		return nil, err
+	}
+	return ethwallet.NewWalletFromRandomEntropy(ethwallet.WalletOptions{
+		DerivationPath:             derivationPath,
+		RandomWalletEntropyBitSize: bits,
+	})
+}
+
 func getWallet(mnemonic, derivationPath string) (*ethwallet.Wallet, error) {
 	var err error
 	var wallet *ethwallet.Wallet
@@ -286,10 +955,7 @@ func getWallet(mnemonic, derivationPath string) (*ethwallet.Wallet, error) {
 	if mnemonic != "" {
 		wallet, err = ethwallet.NewWalletFromMnemonic(mnemonic, derivationPath)
 	} else {
-		wallet, err = ethwallet.NewWalletFromRandomEntropy(ethwallet.WalletOptions{
-			DerivationPath:             derivationPath,
-			RandomWalletEntropyBitSize: ethwallet.EntropyBitSize24WordMnemonic,
-		})
+		wallet, err = newRandomWallet(defaultMnemonicWords, derivationPath)
 	}
 	if err != nil {
 		return nil, err
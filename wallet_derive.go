@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/0xsequence/ethkit/ethrpc"
+	"github.com/0xsequence/ethkit/ethwallet"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagWalletCount        = "count"
+	flagWalletStart        = "start"
+	flagWalletPathTemplate = "path-template"
+	flagWalletPreset       = "preset"
+	flagWalletBalance      = "balance"
+	flagWalletNonce        = "nonce"
+)
+
+// derivation path presets
+const (
+	derivePresetDefault    = "default"
+	derivePresetLedgerLive = "ledger-live"
+	derivePresetMewLegacy  = "mew-legacy"
+)
+
+// derivePresets are the derivation path templates of the common wallets, {i} being the account index.
+var derivePresets = map[string]string{
+	// BIP-44, used by ethkit, MetaMask and most wallets
+	derivePresetDefault: "m/44'/60'/0'/0/{i}",
+	// Ledger Live increments the hardened account level
+	derivePresetLedgerLive: "m/44'/60'/{i}'/0/0",
+	// MyEtherWallet and the Ledger Chrome app used to derive the accounts without the change level
+	derivePresetMewLegacy: "m/44'/60'/0'/{i}",
+}
+
+func derivePresetNames() []string {
+	return []string{derivePresetDefault, derivePresetLedgerLive, derivePresetMewLegacy}
+}
+
+func (c *walletCmd) Derive(cmd *cobra.Command, args []string) error {
+	fKeyFile, err := walletKeyFileFlag(cmd, true)
+	if err != nil {
+		return err
+	}
+	fPath, err := cmd.Flags().GetString(flagWalletPath)
+	if err != nil {
+		return err
+	}
+	fCount, err := cmd.Flags().GetInt(flagWalletCount)
+	if err != nil {
+		return err
+	}
+	fStart, err := cmd.Flags().GetInt(flagWalletStart)
+	if err != nil {
+		return err
+	}
+	fPathTemplate, err := cmd.Flags().GetString(flagWalletPathTemplate)
+	if err != nil {
+		return err
+	}
+	fPreset, err := cmd.Flags().GetString(flagWalletPreset)
+	if err != nil {
+		return err
+	}
+	fBalance, err := cmd.Flags().GetBool(flagWalletBalance)
+	if err != nil {
+		return err
+	}
+	fNonce, err := cmd.Flags().GetBool(flagWalletNonce)
+	if err != nil {
+		return err
+	}
+
+	// a single --path prints the account at this path, as before the account lists
+	if fPath != "" {
+		for _, name := range []string{flagWalletCount, flagWalletStart, flagWalletPathTemplate, flagWalletPreset} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("error: --%s cannot be used with --%s", name, flagWalletPath)
+			}
+		}
+		fPathTemplate, fStart, fCount = fPath, 0, 1
+	} else {
+		if fPathTemplate != "" && cmd.Flags().Changed(flagWalletPreset) {
+			return fmt.Errorf("error: please pass either --%s or --%s", flagWalletPathTemplate, flagWalletPreset)
+		}
+		if fPathTemplate == "" {
+			template, ok := derivePresets[fPreset]
+			if !ok {
+				return fmt.Errorf("error: unknown preset %q, supported: %s", fPreset, strings.Join(derivePresetNames(), ", "))
+			}
+			fPathTemplate = template
+		}
+		if !strings.Contains(fPathTemplate, "{i}") {
+			return errors.New("error: the path template must contain the {i} account index, e.g. \"m/44'/60'/0'/0/{i}\"")
+		}
+	}
+	if fCount < 1 {
+		return fmt.Errorf("error: --%s must be at least 1", flagWalletCount)
+	}
+	if fStart < 0 {
+		return fmt.Errorf("error: --%s cannot be negative", flagWalletStart)
+	}
+
+	paths, err := derivationPaths(fPathTemplate, fStart, fCount)
+	if err != nil {
+		return err
+	}
+
+	var provider *ethrpc.Provider
+	if fBalance || fNonce {
+		if provider, _, err = newProvider(cmd); err != nil {
+			return err
+		}
+	}
+
+	keyFile, err := readWalletKeyFile(fKeyFile)
+	if err != nil {
+		return err
+	}
+	if keyFile.kind() != walletKindMnemonic {
+		return errors.New("error: the keyfile holds a private key, it cannot derive other accounts")
+	}
+
+	passwords, err := passwordSourceFor(cmd)
+	if err != nil {
+		return err
+	}
+	deriveAddress, err := keyFile.unlockAddresses(passwords)
+	if err != nil {
+		return err
+	}
+
+	accounts := make([]*DerivedAccount, len(paths))
+	for i, path := range paths {
+		address, err := deriveAddress(path)
+		if err != nil {
+			return err
+		}
+		accounts[i] = &DerivedAccount{Index: fStart + i, Path: path, Address: address}
+	}
+
+	if fPath != "" {
+		return printResult(cmd, &WalletAccount{Address: accounts[0].Address, Path: accounts[0].Path})
+	}
+
+	ctx := context.Background()
+	for _, account := range accounts {
+		if fBalance {
+			if account.Balance, err = provider.BalanceAt(ctx, account.Address, nil); err != nil {
+				return err
+			}
+		}
+		if fNonce {
+			nonce, err := provider.NonceAt(ctx, account.Address, nil)
+			if err != nil {
+				return err
+			}
+			account.Nonce = &nonce
+		}
+	}
+
+	return printResult(cmd, accounts)
+}
+
+// derivationPaths expands a path template into the derivation paths of count accounts from the start index.
+func derivationPaths(template string, start, count int) ([]string, error) {
+	paths := make([]string, count)
+	for i := range paths {
+		path := strings.ReplaceAll(template, "{i}", strconv.Itoa(start+i))
+		p, err := ethwallet.ParseDerivationPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("error: invalid derivation path %q: %w", path, err)
+		}
+		paths[i] = p.String()
+	}
+	return paths, nil
+}
+
+// DerivedAccount is an account derived from the mnemonic of a wallet key file, along with its balance
+// and nonce when requested.
+type DerivedAccount struct {
+	Index   int            `json:"index"`
+	Path    string         `json:"path"`
+	Address common.Address `json:"address"`
+	Balance *big.Int       `json:"balance,omitempty"`
+	Nonce   *uint64        `json:"nonce,omitempty"`
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/0xsequence/ethkit/ethwallet"
+	"github.com/0xsequence/ethkit/go-ethereum/accounts/keystore"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/spf13/cobra"
+)
+
+const flagWalletDir = "dir"
+
+// Info prints the details of a key file, or of every key file of a directory, stored in clear next to the encrypted
+// secret, so that key files can be audited without their password.
+func (c *walletCmd) Info(cmd *cobra.Command, args []string) error {
+	fKeyFile, err := cmd.Flags().GetString(flagWalletKeyFile)
+	if err != nil {
+		return err
+	}
+	fDir, err := cmd.Flags().GetString(flagWalletDir)
+	if err != nil {
+		return err
+	}
+
+	if (fKeyFile == "") == (fDir == "") {
+		return fmt.Errorf("error: please pass either --%s or --%s", flagWalletKeyFile, flagWalletDir)
+	}
+
+	if fKeyFile != "" {
+		if !fileExists(fKeyFile) {
+			return fmt.Errorf("error: keyfile %s does not exist", fKeyFile)
+		}
+		info := inspectWalletKeyFile(fKeyFile)
+		if err := printResult(cmd, info); err != nil {
+			return err
+		}
+		if !info.Valid {
+			return fmt.Errorf("error: invalid keyfile %s", fKeyFile)
+		}
+		return nil
+	}
+
+	entries, err := os.ReadDir(fDir)
+	if err != nil {
+		return err
+	}
+	infos := WalletInfos{}
+	invalid := 0
+	for _, entry := range entries {
+		// hidden files include the temporary files of an interrupted write
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info := inspectWalletKeyFile(filepath.Join(fDir, entry.Name()))
+		if !info.Valid {
+			invalid++
+		}
+		infos = append(infos, info)
+	}
+
+	if err := printResult(cmd, infos); err != nil {
+		return err
+	}
+	if invalid > 0 {
+		return fmt.Errorf("error: %d of the %d files of %s are not valid keyfiles", invalid, len(infos), fDir)
+	}
+	return nil
+}
+
+// WalletInfo is the clear content of a key file, with the problems found in its schema and security settings.
+type WalletInfo struct {
+	KeyFile    string         `json:"keyfile"`
+	Address    common.Address `json:"address"`
+	Kind       string         `json:"kind"`
+	Path       string         `json:"path"`
+	Passphrase bool           `json:"passphrase"`
+	Client     string         `json:"client"`
+	Cipher     string         `json:"cipher"`
+	KDF        string         `json:"kdf"`
+	KDFParams  map[string]any `json:"kdfparams"`
+	Mode       string         `json:"mode"`
+	Valid      bool           `json:"valid"`
+	Warnings   []string       `json:"warnings"`
+	Errors     []string       `json:"errors"`
+}
+
+// String overrides the standard behavior for WalletInfo "to-string".
+func (w *WalletInfo) String() string {
+	var b strings.Builder
+	for _, row := range [][2]string{
+		{"keyfile", w.KeyFile},
+		{"address", w.Address.Hex()},
+		{"kind", w.Kind},
+		{"path", w.Path},
+		{"passphrase", fmt.Sprint(w.Passphrase)},
+		{"client", w.Client},
+		{"cipher", w.Cipher},
+		{"kdf", w.kdf()},
+		{"mode", w.Mode},
+	} {
+		fmt.Fprintf(&b, "%-20s%s\n", row[0], row[1])
+	}
+	for _, s := range w.Warnings {
+		fmt.Fprintf(&b, "=> warning: %s\n", s)
+	}
+	for _, s := range w.Errors {
+		fmt.Fprintf(&b, "=> error: %s\n", s)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// status summarizes the problems of a key file.
+func (w *WalletInfo) status() string {
+	switch {
+	case !w.Valid:
+		return "invalid"
+	case len(w.Warnings) > 0:
+		return "warning"
+	}
+	return "ok"
+}
+
+// kdf returns the key derivation function with its parameters, e.g. scrypt dklen=32 n=262144 p=1 r=8.
+func (w *WalletInfo) kdf() string {
+	names := make([]string, 0, len(w.KDFParams))
+	for name := range w.KDFParams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	s := w.KDF
+	for _, name := range names {
+		s += fmt.Sprintf(" %s=%v", name, w.KDFParams[name])
+	}
+	return s
+}
+
+// WalletInfos is the audit of the key files of a directory.
+type WalletInfos []*WalletInfo
+
+// String overrides the standard behavior for WalletInfos "to-string", printing a row per key file followed by
+// the problems found.
+func (infos WalletInfos) String() string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEYFILE\tADDRESS\tKIND\tKDF\tMODE\tSTATUS")
+	for _, w := range infos {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", w.KeyFile, w.Address.Hex(), w.Kind, w.kdf(), w.Mode, w.status())
+	}
+	tw.Flush()
+
+	for _, w := range infos {
+		for _, s := range w.Warnings {
+			fmt.Fprintf(&b, "=> warning: %s: %s\n", w.KeyFile, s)
+		}
+		for _, s := range w.Errors {
+			fmt.Fprintf(&b, "=> error: %s: %s\n", w.KeyFile, s)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// inspectWalletKeyFile reads a key file without decrypting it, validating its schema and checking that its
+// KDF settings and file mode are not weaker than the defaults.
+func inspectWalletKeyFile(path string) *WalletInfo {
+	info := &WalletInfo{KeyFile: path, Warnings: []string{}, Errors: []string{}}
+	defer func() { info.Valid = len(info.Errors) == 0 }()
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		info.Errors = append(info.Errors, err.Error())
+		return info
+	}
+	perm := stat.Mode().Perm()
+	info.Mode = fmt.Sprintf("%04o", perm)
+	if perm&0077 != 0 {
+		info.Warnings = append(info.Warnings, fmt.Sprintf("accessible by other users (mode %04o), consider chmod 600", perm))
+	}
+
+	keyFile, err := readWalletKeyFile(path)
+	if err != nil {
+		info.Errors = append(info.Errors, fmt.Sprintf("not a keyfile: %v", err))
+		return info
+	}
+	info.Address, info.Kind, info.Path, info.Client = keyFile.Address, keyFile.kind(), keyFile.Path, keyFile.Client
+	info.Passphrase = keyFile.Passphrase
+	info.Cipher, info.KDF = keyFile.Crypto.Cipher, keyFile.Crypto.KDF
+
+	// the salt is left out as it is only noise to an audit
+	info.KDFParams = map[string]any{}
+	for name, value := range keyFile.Crypto.KDFParams {
+		if name != "salt" {
+			info.KDFParams[name] = value
+		}
+	}
+
+	warnings, errs := keyFile.validate()
+	info.Warnings = append(info.Warnings, warnings...)
+	info.Errors = append(info.Errors, errs...)
+	return info
+}
+
+// validate returns the warnings about the weak settings of a key file, and the errors of its schema.
+func (k *walletKeyFile) validate() (warnings, errs []string) {
+	if k.Address == (common.Address{}) {
+		errs = append(errs, "missing address")
+	}
+
+	switch k.kind() {
+	case walletKindMnemonic:
+		if _, err := ethwallet.ParseDerivationPath(k.Path); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid derivation path %q", k.Path))
+		}
+	case walletKindPrivateKey:
+		if k.Path != "" {
+			errs = append(errs, "a private key keyfile has no derivation path")
+		}
+		if k.Passphrase {
+			errs = append(errs, "a private key keyfile has no BIP-39 passphrase")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("unknown kind %q", k.Kind))
+	}
+
+	c := k.Crypto
+	if c.Cipher != "aes-128-ctr" {
+		errs = append(errs, fmt.Sprintf("unsupported cipher %q", c.Cipher))
+	}
+	if b, err := hex.DecodeString(c.CipherText); err != nil || len(b) == 0 {
+		errs = append(errs, "invalid ciphertext")
+	}
+	if b, err := hex.DecodeString(c.CipherParams.IV); err != nil || len(b) != 16 {
+		errs = append(errs, "invalid cipher iv")
+	}
+	if b, err := hex.DecodeString(c.MAC); err != nil || len(b) != 32 {
+		errs = append(errs, "invalid mac")
+	}
+
+	if salt, ok := c.KDFParams["salt"].(string); !ok || salt == "" {
+		errs = append(errs, "missing kdf salt")
+	} else if _, err := hex.DecodeString(salt); err != nil {
+		errs = append(errs, "invalid kdf salt")
+	}
+	if dklen, err := kdfParamInt(c.KDFParams, "dklen"); err != nil {
+		errs = append(errs, err.Error())
+	} else if dklen < 32 {
+		errs = append(errs, fmt.Sprintf("kdf dklen %d is below 32", dklen))
+	}
+
+	switch c.KDF {
+	case kdfScrypt:
+		n, err := kdfParamInt(c.KDFParams, "n")
+		if err != nil {
+			errs = append(errs, err.Error())
+		} else if n < 2 || n&(n-1) != 0 {
+			errs = append(errs, fmt.Sprintf("scrypt n %d is not a power of 2", n))
+		} else if n < keystore.StandardScryptN {
+			warnings = append(warnings, fmt.Sprintf("weak scrypt n=%d, below the standard %d", n, keystore.StandardScryptN))
+		}
+		for _, name := range []string{"r", "p"} {
+			if v, err := kdfParamInt(c.KDFParams, name); err != nil {
+				errs = append(errs, err.Error())
+			} else if v < 1 {
+				errs = append(errs, fmt.Sprintf("scrypt %s must be at least 1", name))
+			}
+		}
+
+	case kdfPbkdf2:
+		iterations, err := kdfParamInt(c.KDFParams, "c")
+		if err != nil {
+			errs = append(errs, err.Error())
+		} else if iterations < pbkdf2Iterations {
+			warnings = append(warnings, fmt.Sprintf("weak pbkdf2 c=%d, below the standard %d", iterations, pbkdf2Iterations))
+		}
+		if prf, _ := c.KDFParams["prf"].(string); prf != "hmac-sha256" {
+			errs = append(errs, fmt.Sprintf("unsupported pbkdf2 prf %q", prf))
+		}
+
+	default:
+		errs = append(errs, fmt.Sprintf("unsupported kdf %q", c.KDF))
+	}
+
+	return warnings, errs
+}
+
+// kdfParamInt returns an integer parameter of the KDF, decoded from JSON as a float64.
+func kdfParamInt(params map[string]any, name string) (int, error) {
+	v, ok := params[name]
+	if !ok {
+		return 0, fmt.Errorf("missing kdf parameter %s", name)
+	}
+	f, ok := v.(float64)
+	if !ok || f != math.Trunc(f) || math.Abs(f) > math.MaxInt32 {
+		return 0, fmt.Errorf("invalid kdf parameter %s", name)
+	}
+	return int(f), nil
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/0xsequence/ethkit/ethwallet"
+	"github.com/0xsequence/ethkit/go-ethereum/accounts/keystore"
+	"github.com/0xsequence/ethkit/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	flagWalletKdf     = "kdf"
+	flagWalletScryptN = "scrypt-n"
+	flagWalletScryptP = "scrypt-p"
+)
+
+// key derivation functions of the Web3 Secret Storage
+const (
+	kdfScrypt = "scrypt"
+	kdfPbkdf2 = "pbkdf2"
+)
+
+// pbkdf2Iterations is the pbkdf2 iteration count of the Web3 Secret Storage test vectors, as used by geth.
+const pbkdf2Iterations = 262144
+
+// addKdfFlags adds the flags selecting the key derivation function encrypting a key file.
+func addKdfFlags(flags *pflag.FlagSet) {
+	flags.String(flagWalletKdf, kdfScrypt, "The key derivation function of the password: scrypt or pbkdf2")
+	flags.Int(flagWalletScryptN, keystore.StandardScryptN, "The scrypt CPU/memory cost N, a power of 2 (e.g. 4096 for test fixtures)")
+	flags.Int(flagWalletScryptP, keystore.StandardScryptP, "The scrypt parallelization P")
+}
+
+// kdfParams are the key derivation parameters encrypting a key file.
+type kdfParams struct {
+	kdf     string
+	scryptN int
+	scryptP int
+}
+
+// kdfParamsFor returns the validated key derivation parameters of a command.
+func kdfParamsFor(cmd *cobra.Command) (*kdfParams, error) {
+	fKdf, err := cmd.Flags().GetString(flagWalletKdf)
+	if err != nil {
+		return nil, err
+	}
+	fScryptN, err := cmd.Flags().GetInt(flagWalletScryptN)
+	if err != nil {
+		return nil, err
+	}
+	fScryptP, err := cmd.Flags().GetInt(flagWalletScryptP)
+	if err != nil {
+		return nil, err
+	}
+
+	switch fKdf {
+	case kdfScrypt:
+		if fScryptN < 2 || fScryptN&(fScryptN-1) != 0 {
+			return nil, fmt.Errorf("error: --%s must be a power of 2 greater than 1, got %d", flagWalletScryptN, fScryptN)
+		}
+		if fScryptP < 1 {
+			return nil, fmt.Errorf("error: --%s must be at least 1, got %d", flagWalletScryptP, fScryptP)
+		}
+	case kdfPbkdf2:
+		if cmd.Flags().Changed(flagWalletScryptN) || cmd.Flags().Changed(flagWalletScryptP) {
+			return nil, fmt.Errorf("error: --%s and --%s can only be used with --%s %s", flagWalletScryptN, flagWalletScryptP, flagWalletKdf, kdfScrypt)
+		}
+	default:
+		return nil, fmt.Errorf("error: unknown --%s %q, supported: %s, %s", flagWalletKdf, fKdf, kdfScrypt, kdfPbkdf2)
+	}
+
+	return &kdfParams{kdf: fKdf, scryptN: fScryptN, scryptP: fScryptP}, nil
+}
+
+// encryptDataV3 encrypts data with a password in the crypto section format of the Web3 Secret Storage.
+func encryptDataV3(data, password []byte, params *kdfParams) (keystore.CryptoJSON, error) {
+	if params.kdf == kdfScrypt {
+		return keystore.EncryptDataV3(data, password, params.scryptN, params.scryptP)
+	}
+
+	// keystore only encrypts with scrypt, while it decrypts pbkdf2 as well
+	salt := make([]byte, 32)
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return keystore.CryptoJSON{}, err
+	}
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return keystore.CryptoJSON{}, err
+	}
+
+	derivedKey := pbkdf2.Key(password, salt, pbkdf2Iterations, 32, sha256.New)
+	block, err := aes.NewCipher(derivedKey[:16])
+	if err != nil {
+		return keystore.CryptoJSON{}, err
+	}
+	cipherText := make([]byte, len(data))
+	cipher.NewCTR(block, iv).XORKeyStream(cipherText, data)
+
+	// the cipher params type is unexported, so the crypto section is built from its JSON
+	raw, err := json.Marshal(map[string]any{
+		"cipher":       "aes-128-ctr",
+		"ciphertext":   hex.EncodeToString(cipherText),
+		"cipherparams": map[string]string{"iv": hex.EncodeToString(iv)},
+		"kdf":          kdfPbkdf2,
+		"kdfparams": map[string]any{
+			"c":     pbkdf2Iterations,
+			"dklen": 32,
+			"prf":   "hmac-sha256",
+			"salt":  hex.EncodeToString(salt),
+		},
+		"mac": hex.EncodeToString(crypto.Keccak256(derivedKey[16:32], cipherText)),
+	})
+	if err != nil {
+		return keystore.CryptoJSON{}, err
+	}
+
+	var cryptoJSON keystore.CryptoJSON
+	if err := json.Unmarshal(raw, &cryptoJSON); err != nil {
+		return keystore.CryptoJSON{}, err
+	}
+	return cryptoJSON, nil
+}
+
+// keystoreV3 is the JSON of a keystore V3 file.
+type keystoreV3 struct {
+	Address string              `json:"address"`
+	Crypto  keystore.CryptoJSON `json:"crypto"`
+	Id      string              `json:"id"`
+	Version int                 `json:"version"`
+}
+
+// encryptKeystoreV3 returns the keystore V3 JSON of the private key of a wallet.
+func encryptKeystoreV3(wallet *ethwallet.Wallet, password []byte, params *kdfParams) ([]byte, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	cryptoJSON, err := encryptDataV3(crypto.FromECDSA(wallet.PrivateKey()), password, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&keystoreV3{
+		Address: hex.EncodeToString(wallet.Address().Bytes()),
+		Crypto:  cryptoJSON,
+		Id:      id.String(),
+		Version: 3,
+	})
+}
+
+// writeNewFileAtomic writes a file which must not exist yet, its path reserved with O_EXCL before the file is
+// written atomically, so that a file created meanwhile is never overwritten either.
+func writeNewFileAtomic(path string, data []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("error: %s already exists, for safety we do not overwrite existing files", path)
+	}
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	if err := writeFileAtomic(path, data, perm); err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// writeFileAtomic replaces a file by writing a temporary file next to it, synced to disk before being renamed
+// over the file, so that a failure never leaves a partially written file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// sync the directory for the rename to be durable, which is not supported on every platform
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/0xsequence/ethkit/ethwallet"
+	"github.com/0xsequence/ethkit/go-ethereum/accounts/keystore"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+)
+
+// kinds of secret held by a key file
+const (
+	walletKindMnemonic   = "mnemonic"
+	walletKindPrivateKey = "private-key"
+)
+
+// walletFormatKeystoreV3 is the Web3 Secret Storage format of geth keystores, also used by MetaMask and Foundry.
+const walletFormatKeystoreV3 = "keystore-v3"
+
+// readKeystoreV3 decrypts the private key of a keystore V3 file with the next password of a password source.
+func readKeystoreV3(passwords *passwordSource, path string) (*ethwallet.Wallet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pw, err := passwords.read("Keystore Password: ")
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(pw)
+
+	key, err := keystore.DecryptKey(data, string(pw))
+	if err != nil {
+		return nil, fmt.Errorf("error: could not decrypt keystore %s: %w", path, err)
+	}
+
+	return ethwallet.NewWalletFromPrivateKey(hex.EncodeToString(crypto.FromECDSA(key.PrivateKey)))
+}
+
+// exportKeystoreV3 writes the private key of the account at a derivation path to a keystore V3 file, encrypted
+// with a new password.
+func (c *walletCmd) exportKeystoreV3(cmd *cobra.Command, keyFilePath, derivationPath, out string) error {
+	passwords, err := passwordSourceFor(cmd)
+	if err != nil {
+		return err
+	}
+	kdf, err := kdfParamsFor(cmd)
+	if err != nil {
+		return err
+	}
+
+	keyFile, wallet, err := decryptWalletKeyFile(passwords, keyFilePath, derivationPath)
+	if err != nil {
+		return err
+	}
+
+	pw, err := readNewPassword(passwords, "Keystore Password: ", "Confirm Keystore Password: ")
+	if err != nil {
+		return err
+	}
+	defer zeroBytes(pw)
+
+	data, err := encryptKeystoreV3(wallet, pw, kdf)
+	if err != nil {
+		return err
+	}
+
+	// without --out the keystore is printed as is, to be redirected to a file
+	if out == "" {
+		_, err := fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return err
+	}
+
+	if err := writeNewFileAtomic(out, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+
+	return printResult(cmd, &WalletKeystoreExported{File: out, Address: wallet.Address(), Path: keyFile.accountPath(derivationPath)})
+}
+
+// WalletKeystoreExported is the result of the export of an account to a keystore V3 file.
+type WalletKeystoreExported struct {
+	File    string         `json:"file"`
+	Address common.Address `json:"address"`
+	Path    string         `json:"path"`
+}
+
+// String overrides the standard behavior for WalletKeystoreExported "to-string".
+func (e *WalletKeystoreExported) String() string {
+	return fmt.Sprintf("=> success! the keystore of %s has been written to %s", e.Address.Hex(), e.File)
+}
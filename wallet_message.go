@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/0xsequence/ethkit/go-ethereum/accounts"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/0xsequence/ethkit/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+)
+
+const flagWalletVFormat = "v-format"
+
+// signature v formats
+const (
+	vFormatEthereum   = "27"
+	vFormatRecoveryId = "0"
+)
+
+func (c *walletCmd) SignMessage(cmd *cobra.Command, args []string) error {
+	fKeyFile, err := walletKeyFileFlag(cmd, true)
+	if err != nil {
+		return err
+	}
+	fPath, err := cmd.Flags().GetString(flagWalletPath)
+	if err != nil {
+		return err
+	}
+	fVFormat, err := cmd.Flags().GetString(flagWalletVFormat)
+	if err != nil {
+		return err
+	}
+
+	if fVFormat != vFormatEthereum && fVFormat != vFormatRecoveryId {
+		return fmt.Errorf("error: invalid --%s %q, supported: %s (27/28) or %s (0/1)", flagWalletVFormat, fVFormat, vFormatEthereum, vFormatRecoveryId)
+	}
+
+	message, err := parseMessage(args[0])
+	if err != nil {
+		return err
+	}
+
+	_, wallet, err := openWalletKeyFile(cmd, fKeyFile, fPath)
+	if err != nil {
+		return err
+	}
+
+	// crypto.Sign returns the recovery id as v, i.e. 0/1
+	signature, err := crypto.Sign(accounts.TextHash(message), wallet.PrivateKey())
+	if err != nil {
+		return err
+	}
+	if fVFormat == vFormatEthereum {
+		signature[64] += 27
+	}
+
+	return printResult(cmd, &SignedMessage{
+		Address:   wallet.Address(),
+		Message:   hexutil.Bytes(message),
+		Signature: signature,
+	})
+}
+
+func (c *walletCmd) SignTypedData(cmd *cobra.Command, args []string) error {
+	fKeyFile, err := walletKeyFileFlag(cmd, true)
+	if err != nil {
+		return err
+	}
+	fPath, err := cmd.Flags().GetString(flagWalletPath)
+	if err != nil {
+		return err
+	}
+	fVFormat, err := cmd.Flags().GetString(flagWalletVFormat)
+	if err != nil {
+		return err
+	}
+
+	if fVFormat != vFormatEthereum && fVFormat != vFormatRecoveryId {
+		return fmt.Errorf("error: invalid --%s %q, supported: %s (27/28) or %s (0/1)", flagWalletVFormat, fVFormat, vFormatEthereum, vFormatRecoveryId)
+	}
+
+	td, err := readTypedData(args[0])
+	if err != nil {
+		return err
+	}
+	domainSeparator, structHash, digest, err := td.digest()
+	if err != nil {
+		return err
+	}
+
+	_, wallet, err := openWalletKeyFile(cmd, fKeyFile, fPath)
+	if err != nil {
+		return err
+	}
+
+	// the digest is signed as is, without the personal_sign prefix
+	signature, err := crypto.Sign(digest, wallet.PrivateKey())
+	if err != nil {
+		return err
+	}
+	if fVFormat == vFormatEthereum {
+		signature[64] += 27
+	}
+
+	return printResult(cmd, &TypedDataSignature{
+		Address:         wallet.Address(),
+		PrimaryType:     td.PrimaryType,
+		DomainSeparator: domainSeparator,
+		StructHash:      structHash,
+		Digest:          digest,
+		Signature:       signature,
+	})
+}
+
+// SignedMessage is the EIP-191 personal_sign signature of a message.
+type SignedMessage struct {
+	Address   common.Address `json:"address"`
+	Message   hexutil.Bytes  `json:"message"`
+	Signature hexutil.Bytes  `json:"signature"`
+}
+
+// String overrides the standard behavior for SignedMessage "to-string", printing the signature only.
+func (m *SignedMessage) String() string {
+	return m.Signature.String()
+}
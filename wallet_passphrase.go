@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/0xsequence/ethkit/ethwallet"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/crypto"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const flagWalletPassphrase = "passphrase"
+
+// passphraseWallet derives the accounts of a mnemonic with a BIP-39 passphrase, the "25th word", which the
+// ethwallet wallets always derive with an empty passphrase. The passphrase is never saved in the key file,
+// a wallet of another passphrase being a different wallet.
+type passphraseWallet struct {
+	masterKey *hdkeychain.ExtendedKey
+}
+
+func newPassphraseWallet(mnemonic string, passphrase []byte) (*passphraseWallet, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, string(passphrase))
+	if err != nil {
+		return nil, err
+	}
+	masterKey, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		return nil, err
+	}
+	return &passphraseWallet{masterKey: masterKey}, nil
+}
+
+// account returns the wallet of the account at a derivation path, which only holds the account private key.
+func (w *passphraseWallet) account(derivationPath string) (*ethwallet.Wallet, error) {
+	path, err := ethwallet.ParseDerivationPath(derivationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	key := w.masterKey
+	for _, n := range path {
+		if key, err = key.Derive(n); err != nil {
+			return nil, err
+		}
+	}
+	privateKey, err := key.ECPrivKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return ethwallet.NewWalletFromPrivateKey(hex.EncodeToString(crypto.FromECDSA(privateKey.ToECDSA())))
+}
+
+// readNewPassphrase reads a new BIP-39 passphrase, along with its confirmation when it is prompted on the terminal.
+func readNewPassphrase(passwords *passwordSource) ([]byte, error) {
+	passphrase, err := passwords.read("BIP-39 Passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return nil, errors.New("error: the passphrase cannot be empty")
+	}
+
+	if passwords.interactive() {
+		confirm, err := passwords.read("Confirm BIP-39 Passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		if string(passphrase) != string(confirm) {
+			return nil, errors.New("error: passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
+
+// readPasswords reads the password of a key file, followed by its BIP-39 passphrase when it has one.
+func (k *walletKeyFile) readPasswords(passwords *passwordSource) (password, passphrase []byte, err error) {
+	if password, err = passwords.read("Password: "); err != nil {
+		return nil, nil, err
+	}
+	if k.Passphrase {
+		if passphrase, err = passwords.read("BIP-39 Passphrase: "); err != nil {
+			return nil, nil, err
+		}
+	}
+	return password, passphrase, nil
+}
+
+// unlock reads the passwords of a key file and returns the wallet of the account at a derivation path, the stored
+// path by default.
+func (k *walletKeyFile) unlock(passwords *passwordSource, derivationPath string) (*ethwallet.Wallet, error) {
+	password, passphrase, err := k.readPasswords(passwords)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(password)
+	defer zeroBytes(passphrase)
+	return k.decrypt(password, passphrase, derivationPath)
+}
+
+// unlockAddresses reads the passwords of a mnemonic key file and returns a function deriving the account
+// address of any derivation path.
+func (k *walletKeyFile) unlockAddresses(passwords *passwordSource) (func(path string) (common.Address, error), error) {
+	password, passphrase, err := k.readPasswords(passwords)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(password)
+	defer zeroBytes(passphrase)
+	mnemonic, err := k.decryptSecret(password)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(mnemonic)
+
+	if k.Passphrase {
+		w, err := k.openPassphraseWallet(string(mnemonic), passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return func(path string) (common.Address, error) {
+			account, err := w.account(path)
+			if err != nil {
+				return common.Address{}, err
+			}
+			return account.Address(), nil
+		}, nil
+	}
+
+	// the master key of the wallet is kept, only the account key is derived again
+	wallet, err := ethwallet.NewWalletFromMnemonic(string(mnemonic), k.Path)
+	if err != nil {
+		return nil, err
+	}
+	return wallet.SelfDerivePathFromString, nil
+}
+
+// openPassphraseWallet returns the wallet of the mnemonic of a key file with a passphrase, which must derive the
+// stored address as a wrong passphrase still derives a valid, empty, wallet.
+func (k *walletKeyFile) openPassphraseWallet(mnemonic string, passphrase []byte) (*passphraseWallet, error) {
+	w, err := newPassphraseWallet(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	account, err := w.account(k.Path)
+	if err != nil {
+		return nil, err
+	}
+	if account.Address() != k.Address {
+		return nil, fmt.Errorf("error: wrong BIP-39 passphrase, the account at %s is not %s", k.Path, k.Address.Hex())
+	}
+	return w, nil
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"unicode/utf8"
+
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const (
+	flagWalletToFile        = "to-file"
+	flagWalletClipboardFree = "clipboard-free"
+)
+
+// control keys of the masked input
+const (
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyBackspace = 8
+	keyCtrlU     = 21
+	keyDelete    = 127
+)
+
+// zeroBytes overwrites a decrypted secret or a password once it is no longer needed, so that it does not linger
+// in memory until the garbage collector reuses it. This is best effort: the wallet, BIP-39 and keystore libraries
+// only take secrets as strings and keep the keys as big integers, and the results printed hold strings too, none
+// of which can be overwritten. Only the byte slices read and decrypted by ethkit itself are zeroed.
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// readMaskedInput prompts on stderr and reads a secret from the terminal, printing a * for every character typed,
+// or reads a line when stdin is not a terminal.
+func readMaskedInput(prompt string) ([]byte, error) {
+	fd := int(syscall.Stdin)
+	if !terminal.IsTerminal(fd) {
+		line, err := readPlainInput(prompt)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	state, err := terminal.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	input, err := readMasked(stdinReader, os.Stderr)
+	terminal.Restore(fd, state)
+	fmt.Fprintln(os.Stderr)
+	return input, err
+}
+
+// readMasked reads a line from a terminal in raw mode, echoing a * per character. Backspace erases the last
+// character, ctrl-u the whole line, and ctrl-c aborts.
+func readMasked(in io.Reader, echo io.Writer) ([]byte, error) {
+	// the input is kept in a single buffer, grown by hand so that no copy of the secret is left behind
+	input := make([]byte, 0, 256)
+	b := make([]byte, 1)
+	for {
+		if _, err := in.Read(b); err != nil {
+			if err == io.EOF {
+				return input, nil
+			}
+			zeroBytes(input)
+			return nil, err
+		}
+
+		switch c := b[0]; {
+		case c == '\r' || c == '\n' || c == keyCtrlD:
+			return input, nil
+
+		case c == keyCtrlC:
+			zeroBytes(input)
+			return nil, errors.New("error: interrupted")
+
+		case c == keyBackspace || c == keyDelete:
+			if len(input) > 0 {
+				_, size := utf8.DecodeLastRune(input)
+				zeroBytes(input[len(input)-size:])
+				input = input[:len(input)-size]
+				fmt.Fprint(echo, "\b \b")
+			}
+
+		case c == keyCtrlU:
+			fmt.Fprint(echo, strings.Repeat("\b \b", utf8.RuneCount(input)))
+			zeroBytes(input)
+			input = input[:0]
+
+		case c < ' ':
+			// other control keys are ignored
+
+		default:
+			if len(input) == cap(input) {
+				grown := make([]byte, len(input), 2*cap(input))
+				copy(grown, input)
+				zeroBytes(input)
+				input = grown
+			}
+			input = append(input, c)
+			// a single * is printed for the bytes of a multi-byte character
+			if utf8.RuneStart(c) {
+				fmt.Fprint(echo, "*")
+			}
+		}
+	}
+}
+
+// secretDestination is where the secret of an export is revealed, after the --clipboard-free confirmation.
+type secretDestination struct {
+	toFile        string
+	clipboardFree bool
+}
+
+// secretDestinationFor returns the secret destination of a command, checking that --to-file does not exist yet.
+func secretDestinationFor(cmd *cobra.Command) (*secretDestination, error) {
+	fToFile, err := cmd.Flags().GetString(flagWalletToFile)
+	if err != nil {
+		return nil, err
+	}
+	fClipboardFree, err := cmd.Flags().GetBool(flagWalletClipboardFree)
+	if err != nil {
+		return nil, err
+	}
+
+	if fToFile != "" && fileExists(fToFile) {
+		return nil, fmt.Errorf("error: %s already exists, for safety we do not overwrite existing files", fToFile)
+	}
+	return &secretDestination{toFile: fToFile, clipboardFree: fClipboardFree}, nil
+}
+
+// reveal prints the secret of the account at an address, or writes it to --to-file with 0600 permissions. With
+// --clipboard-free the address must first be typed to confirm that this account is the one to reveal.
+func (d *secretDestination) reveal(cmd *cobra.Command, address common.Address, kind string, secret []byte, result any) error {
+	if d.clipboardFree {
+		input, err := readPlainInput(fmt.Sprintf("Type the address %s to reveal its %s: ", address.Hex(), kind))
+		if err != nil {
+			return err
+		}
+		typed := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(string(input))), "0x")
+		if typed != strings.ToLower(address.Hex()[2:]) {
+			return fmt.Errorf("error: the address typed is not %s, the %s is not revealed", address.Hex(), kind)
+		}
+	}
+
+	if d.toFile == "" {
+		return printResult(cmd, result)
+	}
+
+	data := make([]byte, 0, len(secret)+1)
+	data = append(append(data, secret...), '\n')
+	defer zeroBytes(data)
+	if err := writeNewFileAtomic(d.toFile, data, 0600); err != nil {
+		return err
+	}
+	return printResult(cmd, &WalletSecretWritten{File: d.toFile, Address: address, Kind: kind})
+}
+
+// WalletSecretWritten is the result of the export of a secret to a file.
+type WalletSecretWritten struct {
+	File    string         `json:"file"`
+	Address common.Address `json:"address"`
+	Kind    string         `json:"kind"`
+}
+
+// String overrides the standard behavior for WalletSecretWritten "to-string".
+func (w *WalletSecretWritten) String() string {
+	return fmt.Sprintf("=> the %s of %s has been written to %s, readable only by you", w.Kind, w.Address.Hex(), w.File)
+}
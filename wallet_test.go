@@ -0,0 +1,734 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xsequence/ethkit-cli/internal/rpctest"
+	"github.com/0xsequence/ethkit/ethwallet"
+	"github.com/0xsequence/ethkit/go-ethereum/accounts/keystore"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+const testMnemonic = "test test test test test test test test test test test junk"
+
+func execWalletCmd(args string) (string, error) {
+	cmd := NewWalletCmd()
+	actual := new(bytes.Buffer)
+	cmd.SetOut(actual)
+	cmd.SetErr(actual)
+	cmd.SetArgs(strings.Split(args, " "))
+	if err := cmd.Execute(); err != nil {
+		return "", err
+	}
+
+	return actual.String(), nil
+}
+
+// writeTestKeyFile writes a key file of the test mnemonic encrypted with light scrypt parameters.
+func writeTestKeyFile(t *testing.T, password string) (string, *ethwallet.Wallet) {
+	wallet, err := ethwallet.NewWalletFromMnemonic(testMnemonic, ethwallet.DefaultWalletOptions.DerivationPath)
+	assert.Nil(t, err)
+
+	cryptoJSON, err := keystore.EncryptDataV3([]byte(testMnemonic), []byte(password), keystore.LightScryptN, keystore.LightScryptP)
+	assert.Nil(t, err)
+
+	path := filepath.Join(t.TempDir(), "wallet.json")
+	assert.Nil(t, writeWalletKeyFile(path, &walletKeyFile{
+		Address: wallet.Address(),
+		Path:    wallet.HDNode().DerivationPath().String(),
+		Crypto:  cryptoJSON,
+	}))
+
+	return path, wallet
+}
+
+func Test_WalletCmd_InvalidArgs(t *testing.T) {
+	keyFile, _ := writeTestKeyFile(t, "password")
+	missing := filepath.Join(t.TempDir(), "missing.json")
+
+	for _, args := range []string{
+		"new",
+		"import --keyfile " + keyFile,
+		"address",
+		"address --keyfile " + missing,
+		"show --keyfile " + missing,
+		"export --keyfile " + keyFile,
+		"export --keyfile " + keyFile + " --mnemonic --private-key",
+		"derive --keyfile " + keyFile + " --count 0",
+		"derive --keyfile " + keyFile + " --start -1",
+		"derive --keyfile " + keyFile + " --preset trezor",
+		"derive --keyfile " + keyFile + " --path-template m/44'/60'/0'/0/0",
+		"derive --keyfile " + keyFile + " --path-template m/44'/60'/0'/0/{i} --preset ledger-live",
+		"derive --keyfile " + keyFile + " --path m/44'/60'/0'/0/0 --count 2",
+		"derive --keyfile " + keyFile + " --path-template m/44'/60'/{i}'/0/0 --start 2147483647 --count 2",
+		"change-password --keyfile " + missing,
+		"unknown",
+	} {
+		_, err := execWalletCmd(args)
+		assert.NotNil(t, err, args)
+	}
+}
+
+func Test_WalletCmd_Address(t *testing.T) {
+	keyFile, wallet := writeTestKeyFile(t, "password")
+
+	res, err := execWalletCmd("address --keyfile " + keyFile)
+	assert.Nil(t, err)
+	assert.Equal(t, "=> Your Ethereum wallet address is: "+wallet.Address().String()+"\n", res)
+}
+
+func Test_WalletCmd_LegacyFlags(t *testing.T) {
+	for _, tt := range []struct {
+		flags [5]bool
+		args  []string
+	}{
+		{[5]bool{true, false, false, false, true}, []string{"new"}},
+		{[5]bool{false, true, false, false, true}, []string{"import"}},
+		{[5]bool{false, false, true, false, true}, []string{"export", "--mnemonic"}},
+		{[5]bool{false, false, false, true, true}, []string{"export", "--private-key"}},
+		{[5]bool{false, false, false, false, true}, []string{"address"}},
+	} {
+		args, err := legacyWalletArgs(tt.flags[0], tt.flags[1], tt.flags[2], tt.flags[3], tt.flags[4])
+		assert.Nil(t, err)
+		assert.Equal(t, tt.args, args)
+	}
+	_, err := legacyWalletArgs(false, false, false, false, false)
+	assert.EqualError(t, err, "error: not enough options provided to ethkit cli.")
+
+	keyFile, wallet := writeTestKeyFile(t, "password")
+
+	res, err := execWalletCmd("--keyfile " + keyFile + " --print-account")
+	assert.Nil(t, err)
+	assert.Contains(t, res, "Flag --print-account has been deprecated")
+	assert.Contains(t, res, "=> Your Ethereum wallet address is: "+wallet.Address().String())
+
+	_, err = execWalletCmd("--keyfile " + keyFile + " --new")
+	assert.NotNil(t, err)
+
+	_, err = execWalletCmd("--keyfile " + keyFile + " --print-account=false")
+	assert.EqualError(t, err, "error: not enough options provided to ethkit cli.")
+}
+
+func Test_WalletCmd_PasswordSources(t *testing.T) {
+	keyFile, wallet := writeTestKeyFile(t, "password")
+	t.Setenv("ETHKIT_TEST_PASSWORD", "password")
+
+	res, err := execWalletCmd("export --mnemonic --keyfile " + keyFile + " --password-env ETHKIT_TEST_PASSWORD")
+	assert.Nil(t, err)
+	assert.Equal(t, "=> Your Ethereum private mnemonic is:\n=> "+testMnemonic+"\n", res)
+
+	res, err = execWalletCmd("derive --keyfile " + keyFile + " --path m/44'/60'/0'/0/0 --password-env ETHKIT_TEST_PASSWORD")
+	assert.Nil(t, err)
+	assert.Contains(t, res, wallet.Address().String())
+
+	res, err = execWalletCmd("show --keyfile " + keyFile + " --password-env ETHKIT_TEST_PASSWORD")
+	assert.Nil(t, err)
+	assert.Contains(t, res, "kind                | mnemonic\n")
+	assert.Contains(t, res, "address             | "+wallet.Address().Hex()+"\n")
+
+	t.Setenv("ETHKIT_TEST_PASSWORD", "wrong password")
+	_, err = execWalletCmd("show --keyfile " + keyFile + " --password-env ETHKIT_TEST_PASSWORD")
+	assert.NotNil(t, err)
+}
+
+func Test_WalletCmd_PasswordEnvList(t *testing.T) {
+	keyFile, wallet := writeTestKeyFile(t, "password")
+	t.Setenv("ETHKIT_TEST_PASSWORD", "password")
+	t.Setenv("ETHKIT_TEST_NEW_PASSWORD", "new password")
+	t.Setenv("ETHKIT_TEST_PASSPHRASE", "TREZOR")
+
+	_, err := execWalletCmd("change-password --keyfile " + keyFile + " --password-env ETHKIT_TEST_PASSWORD,ETHKIT_TEST_NEW_PASSWORD --scrypt-n 4096")
+	assert.Nil(t, err)
+	k, err := readWalletKeyFile(keyFile)
+	assert.Nil(t, err)
+	w, err := k.decrypt([]byte("new password"), nil, "")
+	assert.Nil(t, err)
+	assert.Equal(t, wallet.Address(), w.Address())
+
+	// the password then the BIP-39 passphrase
+	passphraseFile := filepath.Join(t.TempDir(), "wallet.json")
+	defer func(r *bufio.Reader) { stdinReader = r }(stdinReader)
+	stdinReader = bufio.NewReader(strings.NewReader("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about\n"))
+	res, err := execWalletCmd("import --keyfile " + passphraseFile + " --passphrase --password-env ETHKIT_TEST_PASSWORD,ETHKIT_TEST_PASSPHRASE --scrypt-n 4096")
+	assert.Nil(t, err)
+	assert.Contains(t, res, "0x9c32F71D4DB8Fb9e1A58B0a80dF79935e7256FA6")
+
+	res, err = execWalletCmd("address --keyfile " + passphraseFile)
+	assert.Nil(t, err)
+	assert.Contains(t, res, "0x9c32F71D4DB8Fb9e1A58B0a80dF79935e7256FA6")
+	res, err = execWalletCmd("derive --keyfile " + passphraseFile + " --path m/44'/60'/0'/0/0 --password-env ETHKIT_TEST_PASSWORD,ETHKIT_TEST_PASSPHRASE")
+	assert.Nil(t, err)
+	assert.Contains(t, res, "0x9c32F71D4DB8Fb9e1A58B0a80dF79935e7256FA6")
+}
+
+func Test_WalletCmd_ChangePassword(t *testing.T) {
+	keyFile, wallet := writeTestKeyFile(t, "password")
+	passwordFile := filepath.Join(t.TempDir(), "passwords")
+	assert.Nil(t, os.WriteFile(passwordFile, []byte("password\nnew password\n"), 0600))
+
+	previous, err := os.ReadFile(keyFile)
+	assert.Nil(t, err)
+
+	res, err := execWalletCmd("change-password --keyfile " + keyFile + " --password-file " + passwordFile + " --scrypt-n 4096 --scrypt-p 2")
+	assert.Nil(t, err)
+	backups, err := filepath.Glob(keyFile + ".*.bak")
+	assert.Nil(t, err)
+	assert.Len(t, backups, 1)
+	assert.Regexp(t, `^`+regexp.QuoteMeta(keyFile)+`\.\d+\.bak$`, backups[0])
+	assert.Contains(t, res, backups[0])
+
+	k, err := readWalletKeyFile(keyFile)
+	assert.Nil(t, err)
+	assert.Equal(t, wallet.Address(), k.Address)
+	assert.Equal(t, float64(4096), k.Crypto.KDFParams["n"])
+	assert.Equal(t, float64(2), k.Crypto.KDFParams["p"])
+	w, err := k.decrypt([]byte("new password"), nil, "")
+	assert.Nil(t, err)
+	assert.Equal(t, wallet.Address(), w.Address())
+
+	// the previous keyfile is kept as a backup, and no temporary file is left behind
+	backup, err := os.ReadFile(backups[0])
+	assert.Nil(t, err)
+	assert.Equal(t, previous, backup)
+	entries, err := os.ReadDir(filepath.Dir(keyFile))
+	assert.Nil(t, err)
+	assert.Len(t, entries, 2)
+	info, err := os.Stat(keyFile)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// a second change keeps the first backup, the only copy encrypted with the original password
+	previous, err = os.ReadFile(keyFile)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(passwordFile, []byte("new password\nnewer password\n"), 0600))
+	res, err = execWalletCmd("change-password --keyfile " + keyFile + " --password-file " + passwordFile + " --scrypt-n 4096")
+	assert.Nil(t, err)
+	first := backups[0]
+	backups, err = filepath.Glob(keyFile + ".*.bak")
+	assert.Nil(t, err)
+	assert.Len(t, backups, 2)
+	second := backups[0]
+	if second == first {
+		second = backups[1]
+	}
+	assert.Contains(t, res, second)
+
+	backup, err = os.ReadFile(second)
+	assert.Nil(t, err)
+	assert.Equal(t, previous, backup)
+	k, err = readWalletKeyFile(first)
+	assert.Nil(t, err)
+	_, err = k.decrypt([]byte("password"), nil, "")
+	assert.Nil(t, err)
+	k, err = readWalletKeyFile(keyFile)
+	assert.Nil(t, err)
+	_, err = k.decrypt([]byte("newer password"), nil, "")
+	assert.Nil(t, err)
+}
+
+func Test_KeyFileBackupPath(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "wallet.json")
+	now := time.Unix(1700000000, 0)
+
+	assert.Equal(t, keyFile+".1700000000.bak", keyFileBackupPath(keyFile, now))
+	assert.Nil(t, os.WriteFile(keyFile+".1700000000.bak", nil, 0600))
+	assert.Equal(t, keyFile+".1700000000-1.bak", keyFileBackupPath(keyFile, now))
+}
+
+func Test_WalletCmd_Info(t *testing.T) {
+	keyFile, wallet := writeTestKeyFile(t, "password")
+
+	// no password is needed
+	res, err := execWalletCmd("info --keyfile " + keyFile)
+	assert.Nil(t, err)
+	assert.Contains(t, res, wallet.Address().Hex())
+	assert.Contains(t, res, "scrypt dklen=32 n=4096 p=6 r=8")
+	assert.Contains(t, res, "=> warning: weak scrypt n=4096, below the standard 262144")
+	assert.NotContains(t, res, "accessible by other users")
+
+	// audit a directory with a key file readable by others and a file which is not a key file
+	dir := filepath.Dir(keyFile)
+	data, err := os.ReadFile(keyFile)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "shared.json"), data, 0644))
+	assert.Nil(t, os.Chmod(filepath.Join(dir, "shared.json"), 0644))
+
+	res, err = execWalletCmd("info --dir " + dir)
+	assert.Nil(t, err)
+	assert.Contains(t, res, "=> warning: "+filepath.Join(dir, "shared.json")+": accessible by other users (mode 0644), consider chmod 600")
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("{}"), 0600))
+	_, err = execWalletCmd("info --dir " + dir)
+	assert.NotNil(t, err)
+
+	shared := inspectWalletKeyFile(filepath.Join(dir, "shared.json"))
+	assert.True(t, shared.Valid)
+	assert.Contains(t, shared.Warnings, "accessible by other users (mode 0644), consider chmod 600")
+
+	notes := inspectWalletKeyFile(filepath.Join(dir, "notes.txt"))
+	assert.False(t, notes.Valid)
+	assert.Contains(t, notes.Errors, "missing address")
+	assert.Contains(t, notes.Errors, `unsupported kdf ""`)
+
+	for _, args := range []string{
+		"info",
+		"info --keyfile " + keyFile + " --dir " + dir,
+		"info --keyfile " + filepath.Join(dir, "missing.json"),
+		"info --keyfile " + filepath.Join(dir, "notes.txt"),
+	} {
+		_, err := execWalletCmd(args)
+		assert.NotNil(t, err, args)
+	}
+}
+
+func Test_WalletCmd_Words(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("ETHKIT_TEST_PASSWORD", "password")
+
+	keyFile := filepath.Join(dir, "wallet.json")
+	_, err := execWalletCmd("new --keyfile " + keyFile + " --words 12 --password-env ETHKIT_TEST_PASSWORD --scrypt-n 4096")
+	assert.Nil(t, err)
+
+	k, err := readWalletKeyFile(keyFile)
+	assert.Nil(t, err)
+	mnemonic, err := k.decryptSecret([]byte("password"))
+	assert.Nil(t, err)
+	assert.Len(t, strings.Fields(string(mnemonic)), 12)
+
+	_, err = execWalletCmd("new --keyfile " + filepath.Join(dir, "new.json") + " --words 13 --password-env ETHKIT_TEST_PASSWORD")
+	assert.NotNil(t, err)
+	assert.False(t, fileExists(filepath.Join(dir, "new.json")))
+}
+
+func Test_WalletCmd_ImportInvalidMnemonic(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "wallet.json")
+	t.Setenv("ETHKIT_TEST_PASSWORD", "password")
+
+	defer func(r *bufio.Reader) { stdinReader = r }(stdinReader)
+	stdinReader = bufio.NewReader(strings.NewReader("test test test test test tset test test test test test junk\n"))
+
+	_, err := execWalletCmd("import --keyfile " + keyFile + " --password-env ETHKIT_TEST_PASSWORD")
+	assert.EqualError(t, err, `error: invalid mnemonic, word 6 "tset" is not in the BIP-39 english wordlist, did you mean "test"?`)
+	assert.False(t, fileExists(keyFile))
+}
+
+func Test_ReadPlainInput(t *testing.T) {
+	defer func(r *bufio.Reader) { stdinReader = r }(stdinReader)
+
+	stdinReader = bufio.NewReader(strings.NewReader("y"))
+	input, err := readPlainInput("")
+	assert.Nil(t, err)
+	assert.Equal(t, "y", string(input))
+
+	// a closed stdin is an error rather than an empty answer
+	_, err = readPlainInput("")
+	assert.ErrorContains(t, err, "error: could not read stdin")
+}
+
+func Test_WalletCmd_Passphrase(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "wallet.json")
+	passwordFile := filepath.Join(dir, "passwords")
+
+	// the BIP-39 test vector of the TREZOR passphrase
+	defer func(r *bufio.Reader) { stdinReader = r }(stdinReader)
+	stdinReader = bufio.NewReader(strings.NewReader("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about\n"))
+	assert.Nil(t, os.WriteFile(passwordFile, []byte("password\nTREZOR\n"), 0600))
+
+	res, err := execWalletCmd("import --keyfile " + keyFile + " --passphrase --password-file " + passwordFile + " --scrypt-n 4096")
+	assert.Nil(t, err)
+	assert.Contains(t, res, "0x9c32F71D4DB8Fb9e1A58B0a80dF79935e7256FA6")
+
+	k, err := readWalletKeyFile(keyFile)
+	assert.Nil(t, err)
+	assert.True(t, k.Passphrase)
+
+	res, err = execWalletCmd("info --keyfile " + keyFile)
+	assert.Nil(t, err)
+	assert.Contains(t, res, "passphrase          true")
+
+	// the passphrase is asked for after the password, so the password file of the import unlocks the key file
+	res, err = execWalletCmd("derive --keyfile " + keyFile + " --count 2 --password-file " + passwordFile)
+	assert.Nil(t, err)
+	assert.Contains(t, res, "0x9c32f71d4db8fb9e1a58b0a80df79935e7256fa6")
+	assert.Contains(t, res, "0x7af7283bd1462c3b957e8fac28dc19cbbf2fadfe")
+
+	res, err = execWalletCmd("address --keyfile " + keyFile + " --path m/44'/60'/0'/0/1 --password-file " + passwordFile)
+	assert.Nil(t, err)
+	assert.Contains(t, res, "0x7AF7283bd1462C3b957e8FAc28Dc19cBbF2FAdfe")
+
+	res, err = execWalletCmd("sign-message --keyfile " + keyFile + " --password-file " + passwordFile + " hello")
+	assert.Nil(t, err)
+	signer, err := recoverMessageSigner([]byte("hello"), hexutil.MustDecode(strings.TrimSpace(res)))
+	assert.Nil(t, err)
+	assert.Equal(t, k.Address, signer)
+
+	// the mnemonic is exported without the passphrase, which is not needed to change the password either
+	assert.Nil(t, os.WriteFile(passwordFile, []byte("password\nnew password\n"), 0600))
+	res, err = execWalletCmd("export --mnemonic --keyfile " + keyFile + " --password-file " + passwordFile)
+	assert.Nil(t, err)
+	assert.Contains(t, res, "abandon about")
+	_, err = execWalletCmd("change-password --keyfile " + keyFile + " --password-file " + passwordFile + " --scrypt-n 4096")
+	assert.Nil(t, err)
+
+	k, err = readWalletKeyFile(keyFile)
+	assert.Nil(t, err)
+	assert.True(t, k.Passphrase)
+	w, err := k.decrypt([]byte("new password"), []byte("TREZOR"), "")
+	assert.Nil(t, err)
+	assert.Equal(t, k.Address, w.Address())
+
+	_, err = k.decrypt([]byte("new password"), []byte("trezor"), "")
+	assert.EqualError(t, err, "error: wrong BIP-39 passphrase, the account at m/44'/60'/0'/0/0 is not 0x9c32F71D4DB8Fb9e1A58B0a80dF79935e7256FA6")
+
+	t.Setenv("ETHKIT_TEST_PASSWORD", "password")
+	_, err = execWalletCmd("import --keyfile " + filepath.Join(dir, "key.json") + " --private-key --passphrase --password-env ETHKIT_TEST_PASSWORD")
+	assert.NotNil(t, err)
+}
+
+func Test_WalletCmd_Vanity(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("ETHKIT_TEST_PASSWORD", "password")
+
+	keyFile := filepath.Join(dir, "vanity.json")
+	res, err := execWalletCmd("vanity --keyfile " + keyFile + " --prefix 0xA --case-sensitive --workers 2 --password-env ETHKIT_TEST_PASSWORD --scrypt-n 4096")
+	assert.Nil(t, err)
+	assert.Contains(t, res, "=> found a matching address after")
+
+	k, err := readWalletKeyFile(keyFile)
+	assert.Nil(t, err)
+	assert.Equal(t, walletKindMnemonic, k.kind())
+	assert.True(t, strings.HasPrefix(k.Address.Hex(), "0xA"), k.Address.Hex())
+	w, err := k.decrypt([]byte("password"), nil, "")
+	assert.Nil(t, err)
+	assert.Equal(t, k.Address, w.Address())
+
+	keyFile = filepath.Join(dir, "vanity-key.json")
+	_, err = execWalletCmd("vanity --keyfile " + keyFile + " --prefix 0x0 --suffix 1 --private-key --password-env ETHKIT_TEST_PASSWORD --scrypt-n 4096")
+	assert.Nil(t, err)
+
+	k, err = readWalletKeyFile(keyFile)
+	assert.Nil(t, err)
+	assert.Equal(t, walletKindPrivateKey, k.kind())
+	assert.Regexp(t, "^0x0.*1$", k.Address.Hex())
+
+	for _, args := range []string{
+		"vanity --keyfile " + filepath.Join(dir, "new.json"),
+		"vanity --keyfile " + filepath.Join(dir, "new.json") + " --prefix 0xbeer",
+		"vanity --keyfile " + filepath.Join(dir, "new.json") + " --prefix 0x" + strings.Repeat("0", 40) + " --suffix 0",
+		"vanity --keyfile " + filepath.Join(dir, "new.json") + " --prefix 0 --workers -1",
+		"vanity --keyfile " + filepath.Join(dir, "new.json") + " --prefix 0 --private-key --path m/44'/60'/0'/0/1",
+		"vanity --keyfile " + keyFile + " --prefix 0",
+	} {
+		_, err := execWalletCmd(args + " --password-env ETHKIT_TEST_PASSWORD")
+		assert.NotNil(t, err, args)
+	}
+}
+
+func Test_VanityMatcher(t *testing.T) {
+	address := common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+
+	m, err := newVanityMatcher("0xF39F", "92266", false)
+	assert.Nil(t, err)
+	assert.True(t, m.match(address))
+	assert.Equal(t, float64(1<<36), m.difficulty())
+
+	m, err = newVanityMatcher("0xf39f", "", true)
+	assert.Nil(t, err)
+	assert.False(t, m.match(address))
+	assert.Equal(t, float64(1<<18), m.difficulty())
+
+	m, err = newVanityMatcher("f39F", "2266", true)
+	assert.Nil(t, err)
+	assert.True(t, m.match(address))
+}
+
+func Test_WalletCmd_Pbkdf2(t *testing.T) {
+	dir := t.TempDir()
+	keyFile, wallet := writeTestKeyFile(t, "password")
+	passwordFile := filepath.Join(dir, "passwords")
+	assert.Nil(t, os.WriteFile(passwordFile, []byte("password\nnew password\n"), 0600))
+
+	_, err := execWalletCmd("change-password --keyfile " + keyFile + " --password-file " + passwordFile + " --kdf pbkdf2")
+	assert.Nil(t, err)
+
+	k, err := readWalletKeyFile(keyFile)
+	assert.Nil(t, err)
+	assert.Equal(t, kdfPbkdf2, k.Crypto.KDF)
+	w, err := k.decrypt([]byte("new password"), nil, "")
+	assert.Nil(t, err)
+	assert.Equal(t, wallet.Address(), w.Address())
+
+	// keystores are encrypted with pbkdf2 as well
+	keystoreFile := filepath.Join(dir, "keystore.json")
+	assert.Nil(t, os.WriteFile(passwordFile, []byte("new password\nkeystore password\n"), 0600))
+	_, err = execWalletCmd("export --keyfile " + keyFile + " --format keystore-v3 --kdf pbkdf2 --out " + keystoreFile + " --password-file " + passwordFile)
+	assert.Nil(t, err)
+
+	data, err := os.ReadFile(keystoreFile)
+	assert.Nil(t, err)
+	key, err := keystore.DecryptKey(data, "keystore password")
+	assert.Nil(t, err)
+	assert.Equal(t, wallet.Address(), key.Address)
+}
+
+func Test_WalletCmd_KdfInvalidArgs(t *testing.T) {
+	keyFile, _ := writeTestKeyFile(t, "password")
+	t.Setenv("ETHKIT_TEST_PASSWORD", "password")
+
+	for _, args := range []string{
+		"change-password --keyfile " + keyFile + " --kdf argon2",
+		"change-password --keyfile " + keyFile + " --scrypt-n 1000",
+		"change-password --keyfile " + keyFile + " --scrypt-n 1",
+		"change-password --keyfile " + keyFile + " --scrypt-p 0",
+		"change-password --keyfile " + keyFile + " --kdf pbkdf2 --scrypt-n 4096",
+		"new --keyfile " + filepath.Join(t.TempDir(), "new.json") + " --kdf argon2",
+	} {
+		_, err := execWalletCmd(args + " --password-env ETHKIT_TEST_PASSWORD")
+		assert.NotNil(t, err, args)
+	}
+	assert.False(t, fileExists(keyFile+".bak"))
+}
+
+func Test_WalletCmd_Derive(t *testing.T) {
+	keyFile, _ := writeTestKeyFile(t, "password")
+	t.Setenv("ETHKIT_TEST_PASSWORD", "password")
+
+	res, err := execWalletCmd("derive --keyfile " + keyFile + " --count 3 --start 1 --password-env ETHKIT_TEST_PASSWORD")
+	assert.Nil(t, err)
+	rows := strings.Split(strings.TrimSpace(res), "\n")
+	assert.Len(t, rows, 4)
+	assert.Equal(t, []string{"INDEX", "PATH", "ADDRESS"}, strings.Fields(rows[0]))
+	assert.Equal(t, []string{"1", "m/44'/60'/0'/0/1", "0x70997970c51812dc3a010c7d01b50e0d17dc79c8"}, strings.Fields(rows[1]))
+	assert.Equal(t, []string{"3", "m/44'/60'/0'/0/3", "0x90f79bf6eb2c4f870365e785982e1f101e93b906"}, strings.Fields(rows[3]))
+
+	res, err = execWalletCmd("derive --keyfile " + keyFile + " --path-template m/44'/60'/0'/0/{i} --count 1 --password-env ETHKIT_TEST_PASSWORD")
+	assert.Nil(t, err)
+	assert.Contains(t, res, "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266")
+}
+
+func Test_WalletCmd_DeriveBalanceNonce(t *testing.T) {
+	keyFile, _ := writeTestKeyFile(t, "password")
+	t.Setenv("ETHKIT_TEST_PASSWORD", "password")
+
+	srv := rpctest.NewServer(t, "")
+	srv.Handle("eth_getBalance", nil, "0xde0b6b3a7640000")
+	srv.Handle("eth_getTransactionCount", nil, "0x2a")
+
+	res, err := execWalletCmd("derive --keyfile " + keyFile + " --preset ledger-live --count 2 --balance --nonce -r " + srv.URL + " --password-env ETHKIT_TEST_PASSWORD")
+	assert.Nil(t, err)
+	rows := strings.Split(strings.TrimSpace(res), "\n")
+	assert.Len(t, rows, 3)
+	assert.Equal(t, []string{"INDEX", "PATH", "ADDRESS", "BALANCE", "NONCE"}, strings.Fields(rows[0]))
+	assert.Equal(t, []string{"1", "m/44'/60'/1'/0/0", "1000000000000000000", "42"}, append(strings.Fields(rows[2])[:2], strings.Fields(rows[2])[3:]...))
+}
+
+func Test_WalletCmd_SignMessage(t *testing.T) {
+	keyFile, wallet := writeTestKeyFile(t, "password")
+	t.Setenv("ETHKIT_TEST_PASSWORD", "password")
+
+	expected, err := wallet.SignMessage([]byte("hello"))
+	assert.Nil(t, err)
+
+	res, err := execWalletCmd("sign-message --keyfile " + keyFile + " --password-env ETHKIT_TEST_PASSWORD hello")
+	assert.Nil(t, err)
+	assert.Equal(t, hexutil.Encode(expected)+"\n", res)
+
+	res, err = execWalletCmd("sign-message --keyfile " + keyFile + " --password-env ETHKIT_TEST_PASSWORD --v-format 0 0x68656c6c6f")
+	assert.Nil(t, err)
+	expected[64] -= 27
+	assert.Equal(t, hexutil.Encode(expected)+"\n", res)
+
+	_, err = execWalletCmd("sign-message --keyfile " + keyFile + " --password-env ETHKIT_TEST_PASSWORD --v-format 1 hello")
+	assert.NotNil(t, err)
+}
+
+func Test_WalletCmd_SignTypedData(t *testing.T) {
+	keyFile, wallet := writeTestKeyFile(t, "password")
+	t.Setenv("ETHKIT_TEST_PASSWORD", "password")
+
+	cmd := NewWalletCmd()
+	cmd.PersistentFlags().StringP(flagOutput, "o", outputTable, "")
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"sign-typed-data", "--keyfile", keyFile, "--password-env", "ETHKIT_TEST_PASSWORD", "-o", "template={{.signature}}", mailTypedDataFile})
+	assert.Nil(t, cmd.Execute())
+
+	signature := strings.TrimSpace(buf.String())
+	res, err := execTypedDataCmd("verify " + mailTypedDataFile + " --signature " + signature + " --address " + wallet.Address().Hex())
+	assert.Nil(t, err)
+	assert.Equal(t, "=> valid signature by "+wallet.Address().Hex()+"\n", res)
+}
+
+func Test_WalletCmd_KeystoreV3(t *testing.T) {
+	dir := t.TempDir()
+	keyFile, wallet := writeTestKeyFile(t, "password")
+	passwordFile := filepath.Join(dir, "passwords")
+	assert.Nil(t, os.WriteFile(passwordFile, []byte("password\nkeystore password\n"), 0600))
+
+	// export the second account of the keyfile to a keystore
+	keystoreFile := filepath.Join(dir, "keystore.json")
+	res, err := execWalletCmd("export --keyfile " + keyFile + " --path m/44'/60'/0'/0/1 --format keystore-v3 --out " + keystoreFile + " --password-file " + passwordFile + " --scrypt-n 4096")
+	assert.Nil(t, err)
+	assert.Equal(t, "=> success! the keystore of 0x70997970C51812dc3A010C7d01b50e0d17dc79C8 has been written to "+keystoreFile+"\n", res)
+
+	data, err := os.ReadFile(keystoreFile)
+	assert.Nil(t, err)
+	key, err := keystore.DecryptKey(data, "keystore password")
+	assert.Nil(t, err)
+	account, _, err := wallet.DerivePathFromString("m/44'/60'/0'/0/1")
+	assert.Nil(t, err)
+	assert.Equal(t, account.Address(), key.Address)
+
+	// and import it back as a private key keyfile
+	importedFile := filepath.Join(dir, "imported.json")
+	assert.Nil(t, os.WriteFile(passwordFile, []byte("keystore password\nnew password\n"), 0600))
+	_, err = execWalletCmd("import --keyfile " + importedFile + " --keystore " + keystoreFile + " --password-file " + passwordFile + " --scrypt-n 4096")
+	assert.Nil(t, err)
+
+	imported, err := readWalletKeyFile(importedFile)
+	assert.Nil(t, err)
+	assert.Equal(t, walletKindPrivateKey, imported.Kind)
+	assert.Equal(t, "", imported.Path)
+	assert.Equal(t, account.Address(), imported.Address)
+
+	t.Setenv("ETHKIT_TEST_PASSWORD", "new password")
+	res, err = execWalletCmd("export --private-key --keyfile " + importedFile + " --password-env ETHKIT_TEST_PASSWORD")
+	assert.Nil(t, err)
+	assert.Equal(t, "=> Your Ethereum private key is:\n=> "+account.PrivateKeyHex()+"\n", res)
+
+	for _, args := range []string{
+		"export --mnemonic --keyfile " + importedFile,
+		"derive --keyfile " + importedFile,
+		"derive --keyfile " + importedFile + " --path m/44'/60'/0'/0/1",
+	} {
+		_, err = execWalletCmd(args + " --password-env ETHKIT_TEST_PASSWORD")
+		assert.NotNil(t, err, args)
+	}
+}
+
+func Test_WalletCmd_ImportPrivateKey(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "wallet.json")
+	t.Setenv("ETHKIT_TEST_PASSWORD", "password")
+
+	defer func(r *bufio.Reader) { stdinReader = r }(stdinReader)
+	stdinReader = bufio.NewReader(strings.NewReader("0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80\n"))
+
+	res, err := execWalletCmd("import --private-key --keyfile " + keyFile + " --password-env ETHKIT_TEST_PASSWORD --scrypt-n 4096")
+	assert.Nil(t, err)
+	assert.Contains(t, res, "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+
+	k, err := readWalletKeyFile(keyFile)
+	assert.Nil(t, err)
+	assert.Equal(t, walletKindPrivateKey, k.Kind)
+}
+
+func Test_WriteNewFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keystore.json")
+
+	assert.Nil(t, writeNewFileAtomic(path, []byte("keystore\n"), 0600))
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// an existing file is left as is, and no temporary file is left behind
+	err = writeNewFileAtomic(path, []byte("other\n"), 0600)
+	assert.EqualError(t, err, "error: "+path+" already exists, for safety we do not overwrite existing files")
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "keystore\n", string(data))
+	entries, err := os.ReadDir(filepath.Dir(path))
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func Test_WalletCmd_KeystoreInvalidArgs(t *testing.T) {
+	keyFile, _ := writeTestKeyFile(t, "password")
+	dir := t.TempDir()
+
+	for _, args := range []string{
+		"export --keyfile " + keyFile + " --format pem",
+		"export --keyfile " + keyFile + " --format keystore-v3 --mnemonic",
+		"export --keyfile " + keyFile + " --format keystore-v3 --out " + keyFile,
+		"export --keyfile " + keyFile + " --private-key --out " + filepath.Join(dir, "key"),
+		"import --keyfile " + filepath.Join(dir, "new.json") + " --keystore " + filepath.Join(dir, "missing.json"),
+		"import --keyfile " + filepath.Join(dir, "new.json") + " --keystore " + keyFile + " --private-key",
+		"import --keyfile " + filepath.Join(dir, "new.json") + " --private-key --path m/44'/60'/0'/0/1",
+	} {
+		_, err := execWalletCmd(args)
+		assert.NotNil(t, err, args)
+	}
+}
+
+func Test_WalletCmd_ExportToFile(t *testing.T) {
+	keyFile, wallet := writeTestKeyFile(t, "password")
+	t.Setenv("ETHKIT_TEST_PASSWORD", "password")
+	dir := t.TempDir()
+
+	out := filepath.Join(dir, "mnemonic.txt")
+	res, err := execWalletCmd("export --mnemonic --keyfile " + keyFile + " --password-env ETHKIT_TEST_PASSWORD --to-file " + out)
+	assert.Nil(t, err)
+	assert.NotContains(t, res, testMnemonic)
+	assert.Contains(t, res, "has been written to "+out)
+
+	data, err := os.ReadFile(out)
+	assert.Nil(t, err)
+	assert.Equal(t, testMnemonic+"\n", string(data))
+	info, err := os.Stat(out)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// the secret is never written over an existing file
+	_, err = execWalletCmd("export --private-key --keyfile " + keyFile + " --password-env ETHKIT_TEST_PASSWORD --to-file " + out)
+	assert.NotNil(t, err)
+
+	out = filepath.Join(dir, "key.txt")
+	_, err = execWalletCmd("export --private-key --keyfile " + keyFile + " --password-env ETHKIT_TEST_PASSWORD --to-file " + out)
+	assert.Nil(t, err)
+	data, err = os.ReadFile(out)
+	assert.Nil(t, err)
+	assert.Equal(t, wallet.PrivateKeyHex()+"\n", string(data))
+
+	_, err = execWalletCmd("export --format keystore-v3 --keyfile " + keyFile + " --to-file " + filepath.Join(dir, "keystore.json"))
+	assert.NotNil(t, err)
+}
+
+func Test_WalletCmd_ExportClipboardFree(t *testing.T) {
+	keyFile, wallet := writeTestKeyFile(t, "password")
+	t.Setenv("ETHKIT_TEST_PASSWORD", "password")
+	defer func(r *bufio.Reader) { stdinReader = r }(stdinReader)
+
+	stdinReader = bufio.NewReader(strings.NewReader(strings.ToLower(wallet.Address().Hex()) + "\n"))
+	res, err := execWalletCmd("export --mnemonic --clipboard-free --keyfile " + keyFile + " --password-env ETHKIT_TEST_PASSWORD")
+	assert.Nil(t, err)
+	assert.Contains(t, res, testMnemonic)
+
+	stdinReader = bufio.NewReader(strings.NewReader("0x70997970C51812dc3A010C7d01b50e0d17dc79C8\n"))
+	res, err = execWalletCmd("export --private-key --clipboard-free --keyfile " + keyFile + " --password-env ETHKIT_TEST_PASSWORD")
+	assert.EqualError(t, err, "error: the address typed is not "+wallet.Address().Hex()+", the private key is not revealed")
+	assert.NotContains(t, res, wallet.PrivateKeyHex())
+}
+
+func Test_ReadMasked(t *testing.T) {
+	echo := new(bytes.Buffer)
+	input, err := readMasked(strings.NewReader("tesx\x7ft é\r"), echo)
+	assert.Nil(t, err)
+	assert.Equal(t, "test é", string(input))
+	assert.Equal(t, "****\b \b***", echo.String())
+
+	input, err = readMasked(strings.NewReader("wrong\x15right\n"), new(bytes.Buffer))
+	assert.Nil(t, err)
+	assert.Equal(t, "right", string(input))
+
+	_, err = readMasked(strings.NewReader("secret\x03"), new(bytes.Buffer))
+	assert.NotNil(t, err)
+}
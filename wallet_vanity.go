@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xsequence/ethkit/ethwallet"
+	"github.com/0xsequence/ethkit/go-ethereum/common"
+	"github.com/0xsequence/ethkit/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagWalletPrefix        = "prefix"
+	flagWalletSuffix        = "suffix"
+	flagWalletCaseSensitive = "case-sensitive"
+	flagWalletWorkers       = "workers"
+)
+
+// vanityProgressInterval is the interval between the progress reports of a vanity address search.
+var vanityProgressInterval = 2 * time.Second
+
+var vanityPattern = regexp.MustCompile(`^[0-9a-fA-F]*$`)
+
+// Vanity creates a new key file for a random wallet whose address matches a prefix and a suffix.
+func (c *walletCmd) Vanity(cmd *cobra.Command, args []string) error {
+	fPrefix, err := cmd.Flags().GetString(flagWalletPrefix)
+	if err != nil {
+		return err
+	}
+	fSuffix, err := cmd.Flags().GetString(flagWalletSuffix)
+	if err != nil {
+		return err
+	}
+	fCaseSensitive, err := cmd.Flags().GetBool(flagWalletCaseSensitive)
+	if err != nil {
+		return err
+	}
+	fWorkers, err := cmd.Flags().GetInt(flagWalletWorkers)
+	if err != nil {
+		return err
+	}
+	fPrivateKey, err := cmd.Flags().GetBool(flagWalletPrivateKey)
+	if err != nil {
+		return err
+	}
+	fWords, err := cmd.Flags().GetInt(flagMnemonicWords)
+	if err != nil {
+		return err
+	}
+
+	matcher, err := newVanityMatcher(fPrefix, fSuffix, fCaseSensitive)
+	if err != nil {
+		return err
+	}
+	if fWorkers < 0 {
+		return fmt.Errorf("error: --%s cannot be negative", flagWalletWorkers)
+	}
+	if fWorkers == 0 {
+		fWorkers = runtime.NumCPU()
+	}
+	if _, err := mnemonicEntropyBits(fWords); err != nil {
+		return err
+	}
+	for _, name := range []string{flagWalletPath, flagMnemonicWords} {
+		if fPrivateKey && cmd.Flags().Changed(name) {
+			return fmt.Errorf("error: --%s cannot be used with --%s", name, flagWalletPrivateKey)
+		}
+	}
+
+	return c.create(cmd, func(passwords *passwordSource, derivationPath string) (*ethwallet.Wallet, error) {
+		generate := func() (common.Address, func() (*ethwallet.Wallet, error), error) {
+			wallet, err := newRandomWallet(fWords, derivationPath)
+			if err != nil {
+				return common.Address{}, nil, err
+			}
+			return wallet.Address(), func() (*ethwallet.Wallet, error) { return wallet, nil }, nil
+		}
+		if fPrivateKey {
+			// only the address is computed for every key, the wallet being built for the matching one
+			generate = func() (common.Address, func() (*ethwallet.Wallet, error), error) {
+				key, err := crypto.GenerateKey()
+				if err != nil {
+					return common.Address{}, nil, err
+				}
+				return crypto.PubkeyToAddress(key.PublicKey), func() (*ethwallet.Wallet, error) {
+					return ethwallet.NewWalletFromPrivateKey(hex.EncodeToString(crypto.FromECDSA(key)))
+				}, nil
+			}
+		}
+
+		return searchVanity(cmd.ErrOrStderr(), matcher, fWorkers, generate)
+	})
+}
+
+// vanityMatcher matches the hex of addresses, checksummed when case sensitive.
+type vanityMatcher struct {
+	prefix        string
+	suffix        string
+	caseSensitive bool
+}
+
+func newVanityMatcher(prefix, suffix string, caseSensitive bool) (*vanityMatcher, error) {
+	prefix = strings.TrimPrefix(prefix, "0x")
+	if prefix == "" && suffix == "" {
+		return nil, fmt.Errorf("error: please pass --%s and/or --%s", flagWalletPrefix, flagWalletSuffix)
+	}
+	if !vanityPattern.MatchString(prefix) || !vanityPattern.MatchString(suffix) {
+		return nil, fmt.Errorf("error: --%s and --%s must be hex characters (0-9, a-f)", flagWalletPrefix, flagWalletSuffix)
+	}
+	if len(prefix)+len(suffix) > 2*common.AddressLength {
+		return nil, fmt.Errorf("error: --%s and --%s are longer than an address", flagWalletPrefix, flagWalletSuffix)
+	}
+	if !caseSensitive {
+		prefix, suffix = strings.ToLower(prefix), strings.ToLower(suffix)
+	}
+	return &vanityMatcher{prefix: prefix, suffix: suffix, caseSensitive: caseSensitive}, nil
+}
+
+func (m *vanityMatcher) match(address common.Address) bool {
+	var s string
+	if m.caseSensitive {
+		s = address.Hex()[2:]
+	} else {
+		s = hex.EncodeToString(address.Bytes())
+	}
+	return strings.HasPrefix(s, m.prefix) && strings.HasSuffix(s, m.suffix)
+}
+
+// difficulty returns the number of addresses generated on average to find a match, every hex character
+// dividing the matching addresses by 16, and the case of a letter by 2 more.
+func (m *vanityMatcher) difficulty() float64 {
+	pattern := m.prefix + m.suffix
+	d := math.Pow(16, float64(len(pattern)))
+	if m.caseSensitive {
+		for _, r := range pattern {
+			if r > '9' {
+				d *= 2
+			}
+		}
+	}
+	return d
+}
+
+func (m *vanityMatcher) String() string {
+	return "0x" + m.prefix + "…" + m.suffix
+}
+
+// searchVanity generates addresses with a number of workers until one matches, reporting the throughput and
+// the estimated time to a match.
+func searchVanity(w io.Writer, matcher *vanityMatcher, workers int, generate func() (common.Address, func() (*ethwallet.Wallet, error), error)) (*ethwallet.Wallet, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	difficulty := matcher.difficulty()
+	fmt.Fprintf(w, "=> searching for an address matching %s with %d workers, 1 in %.0f addresses match\n", matcher, workers, difficulty)
+
+	var attempts atomic.Uint64
+	found := make(chan func() (*ethwallet.Wallet, error), 1)
+	errs := make(chan error, 1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				address, wallet, err := generate()
+				if err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+					cancel()
+					return
+				}
+				attempts.Add(1)
+				if matcher.match(address) {
+					select {
+					case found <- wallet:
+					default:
+					}
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+
+	start := time.Now()
+	ticker := time.NewTicker(vanityProgressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case wallet := <-found:
+			cancel()
+			wg.Wait()
+			elapsed := time.Since(start)
+			n := attempts.Load()
+			fmt.Fprintf(w, "=> found a matching address after %d addresses in %s (%.0f/s)\n", n, elapsed.Round(time.Millisecond), float64(n)/elapsed.Seconds())
+			return wallet()
+
+		case err := <-errs:
+			wg.Wait()
+			return nil, err
+
+		case <-ticker.C:
+			elapsed := time.Since(start)
+			n := attempts.Load()
+			rate := float64(n) / elapsed.Seconds()
+			chance := -math.Expm1(float64(n) * math.Log1p(-1/difficulty))
+			// the search is memoryless, the addresses already tried do not bring a match closer
+			fmt.Fprintf(w, "=> %d addresses tried (%.0f/s), %.1f%% chance of a match so far, %s expected to a match\n", n, rate, 100*chance, vanityETA(difficulty, rate))
+		}
+	}
+}
+
+// vanityETA returns the average time to a match at a rate of addresses per second.
+func vanityETA(difficulty, rate float64) string {
+	if rate == 0 {
+		return "unknown time"
+	}
+	seconds := difficulty / rate
+	if seconds > 100*365*24*3600 {
+		return "more than 100 years"
+	}
+	return "~" + (time.Duration(seconds) * time.Second).String()
+}